@@ -22,6 +22,7 @@ import (
 	authDelivery "team_exe/internal/delivery/auth"
 	gameDelivery "team_exe/internal/delivery/game"
 	katagoDelivery "team_exe/internal/delivery/katago"
+	"team_exe/internal/domain/rank"
 	ownMiddleware "team_exe/internal/middleware"
 	katagoProto "team_exe/microservices/proto"
 )
@@ -54,6 +55,8 @@ func main() {
 		return
 	}
 
+	rank.DefaultScale = rank.Scale{DanRating: cfg.RankDanRating, Step: cfg.RankStep}.WithDefaults()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -99,11 +102,19 @@ func (h *mainDeliveryHandler) Router(r *chi.Mux, isLocalCors bool) {
 	r.Post("/register", h.auth.Register)
 	r.Post("/autoBotGenerateMove", h.katago.HandleGenerateMove)
 	r.Post("/NewGame", h.game.HandleNewGame)
+	r.Post("/newBotGame", h.game.HandleNewBotGame)
+	r.Get("/botLevels", h.game.HandleBotLevels)
 	r.Post("/JoinGame", h.game.HandleJoinGame)
 	r.Get("/startGame", h.game.HandleStartGame)
 	r.Post("/getGameByPublicKey", h.game.HandleGetGameByPublicKey)
+	r.Post("/suggestHandicap", h.game.HandleSuggestHandicap)
+	r.Post("/joinMatchmaking", h.game.HandleJoinMatchmaking)
+	r.Post("/leaveMatchmaking", h.game.HandleLeaveMatchmaking)
+	r.Get("/matchmakingStatus", h.game.HandleMatchmakingStatus)
 	r.Post("/leaveGame", h.game.LeaveGame)
 	r.Post("/getUserById", h.auth.GetUserByID)
+	r.Get("/getRatingHistory", h.auth.GetRatingHistory)
+	r.Post("/recomputeStatistics", h.game.HandleRecomputeStatistics)
 	r.Get("/getArchive", h.game.HandleGetArchivePaginator)
 	r.Get("/getYearsInArchive", h.game.HandleGetYearsInArchive)
 	r.Get("/getNamesInArchive", h.game.HandleGetNamesInArchive)
@@ -141,7 +152,9 @@ func initializeDeliveryHandlers(
 	katagoDeliveryHandler := katagoDelivery.NewKatagoHandler(cfg, log, katagoManager)
 
 	authDeliveryHandler := authDelivery.NewAuthHandler(databaseAdapters.redisAdapter, databaseAdapters.mongoAdapter, log)
-	gameDeliveryHandler := gameDelivery.NewGameHandler(cfg, log, databaseAdapters.mongoAdapter, databaseAdapters.redisAdapter, authDeliveryHandler)
+	gameDeliveryHandler := gameDelivery.NewGameHandler(cfg, log, databaseAdapters.mongoAdapter, databaseAdapters.redisAdapter, authDeliveryHandler, katagoManager)
+	gameDeliveryHandler.RestoreActiveGames(ctx)
+	go gameDeliveryHandler.RunMatchmaking(ctx)
 
 	return &mainDeliveryHandler{
 		auth:   authDeliveryHandler,
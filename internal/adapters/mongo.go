@@ -76,5 +76,14 @@ func (a *AdapterMongo) InitIndexes(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("ошибка создания индексов: %w", err)
 	}
+
+	// у игрока не больше одной записи истории рейтинга на партию
+	_, err = a.Database.Collection("rating_history").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "game_key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка создания индексов: %w", err)
+	}
 	return nil
 }
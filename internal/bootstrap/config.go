@@ -1,19 +1,32 @@
 package bootstrap
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	ServerPort       string `mapstructure:"SERVER_PORT"`
-	GpuServerIp      string `mapstructure:"GPU_SERVER_IP"`
-	GpuServerPort    string `mapstructure:"GPU_SERVER_PORT"`
-	KatagoBotUrl     string `mapstructure:"KATAGO_BOT_URL"`
-	RedisUrl         string `mapstructure:"REDIS_URL"`
-	MongoUri         string `mapstructure:"MONGO_URI"`
-	IsLocalCors      bool   `mapstructure:"LOCAL_CORS"`
-	PageLimitGames   int    `mapstructure:"PAGE_LIMIT_GAMES"`
-	PageLimitPlayers int    `mapstructure:"PAGE_LIMIT_PLAYERS"`
+	ServerPort                 string        `mapstructure:"SERVER_PORT"`
+	GpuServerIp                string        `mapstructure:"GPU_SERVER_IP"`
+	GpuServerPort              string        `mapstructure:"GPU_SERVER_PORT"`
+	KatagoBotUrl               string        `mapstructure:"KATAGO_BOT_URL"`
+	RedisUrl                   string        `mapstructure:"REDIS_URL"`
+	MongoUri                   string        `mapstructure:"MONGO_URI"`
+	IsLocalCors                bool          `mapstructure:"LOCAL_CORS"`
+	PageLimitGames             int           `mapstructure:"PAGE_LIMIT_GAMES"`
+	PageLimitPlayers           int           `mapstructure:"PAGE_LIMIT_PLAYERS"`
+	SgfTTL                     time.Duration `mapstructure:"SGF_TTL"`
+	RankDanRating              float64       `mapstructure:"RANK_DAN_RATING"`
+	RankStep                   float64       `mapstructure:"RANK_STEP"`
+	MatchmakingWindowBase      float64       `mapstructure:"MATCHMAKING_WINDOW_BASE"`
+	MatchmakingWindowPerMinute float64       `mapstructure:"MATCHMAKING_WINDOW_PER_MINUTE"`
+	MatchmakingWindowMax       float64       `mapstructure:"MATCHMAKING_WINDOW_MAX"`
+	WsPingInterval             time.Duration `mapstructure:"WS_PING_INTERVAL"`
+	WsPongTimeout              time.Duration `mapstructure:"WS_PONG_TIMEOUT"`
+	AbandonGrace               time.Duration `mapstructure:"ABANDON_GRACE"`
+	AbandonMinMoves            int           `mapstructure:"ABANDON_MIN_MOVES"`
+	GameLeaseTTL               time.Duration `mapstructure:"GAME_LEASE_TTL"`
 }
 
 func Setup(cfgPath string) (*Config, error) {
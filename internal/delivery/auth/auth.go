@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"team_exe/internal/adapters"
+	_ "team_exe/internal/domain/rating"
 	_ "team_exe/internal/domain/user"
 	errs "team_exe/internal/errors"
 	"team_exe/internal/httpresponse"
@@ -295,3 +296,42 @@ func (a *AuthHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 
 	httpresponse.WriteResponseWithStatus(w, http.StatusOK, user)
 }
+
+// GetRatingHistory godoc
+// @Summary История рейтинга пользователя
+// @Description Возвращает текущий рейтинг Glicko-2 и его изменения после каждой рейтинговой партии для построения графика. Без user_id возвращает историю текущего пользователя. Требуется авторизация (cookie sessionID).
+// @Tags user
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Success 200 {object} rating.HistoryResponse
+// @Failure 400 {object} httpresponse.ErrorResponse
+// @Failure 401 {object} httpresponse.ErrorResponse
+// @Failure 405 {string} string "Only GET method is allowed"
+// @Router /getRatingHistory [get]
+func (a *AuthHandler) GetRatingHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		a.log.Error("GetRatingHistory: only GET method is allowed")
+		httpresponse.WriteResponseWithStatus(w, http.StatusMethodNotAllowed, "Only GET method is allowed")
+		return
+	}
+
+	currentUserID := a.GetUserID(w, r)
+	if currentUserID == "" {
+		return
+	}
+
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		userID = currentUserID
+	}
+
+	history, err := a.UsecaseHandler.GetRatingHistory(r.Context(), userID)
+	if err != nil {
+		a.log.Errorf("GetRatingHistory: error retrieving rating history of %s: %v", userID, err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest,
+			httpresponse.ErrorResponse{ErrorDescription: err.Error()})
+		return
+	}
+
+	httpresponse.WriteResponseWithStatus(w, http.StatusOK, history)
+}
@@ -0,0 +1,198 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/bot"
+	"team_exe/internal/domain/game"
+	"team_exe/internal/domain/protocol"
+	errs "team_exe/internal/errors"
+	"team_exe/internal/httpresponse"
+	"team_exe/internal/statuses"
+	katagoUC "team_exe/internal/usecase/katago"
+	"team_exe/internal/utils"
+	"time"
+)
+
+// Запросы хода у KataGo: сколько раз пробовать и сколько ждать ответа.
+const (
+	botRetries     = 3
+	botRetryDelay  = 2 * time.Second
+	botMoveTimeout = 30 * time.Second
+)
+
+// HandleNewBotGame godoc
+// @Summary Создать партию против бота
+// @Description Создаёт партию текущего пользователя против KataGo. Бот играет выбранным цветом (по умолчанию белыми) и отвечает на ходы через сокет /startGame. Партия сохраняется в истории как обычная. Требуется авторизация через cookie.
+// @Tags game
+// @Accept json
+// @Produce json
+// @Param request body game.CreateBotGameRequest true "Размер доски до 19x19, коми, правила, фора, цвет и уровень бота"
+// @Success 200 {object} game.GameCreateResponse "Игра успешно создана"
+// @Failure 400 {object} httpresponse.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} httpresponse.ErrorResponse "Пользователь не авторизован"
+// @Failure 405 {object} httpresponse.ErrorResponse "Метод не разрешен"
+// @Router /newBotGame [post]
+func (g *GameHandler) HandleNewBotGame(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		g.log.Error("Разрешен только метод POST")
+		httpresponse.WriteResponseWithStatus(w, http.StatusMethodNotAllowed, "Разрешен только метод POST")
+		return
+	}
+
+	userID := g.authHandler.GetUserID(w, r)
+	if userID == "" {
+		g.log.Error("UserID не найден в cookie")
+		httpresponse.WriteResponseWithStatus(w, http.StatusUnauthorized, "UserID не найден в cookie")
+		return
+	}
+
+	var req game.CreateBotGameRequest
+	if err := utils.DecodeJSONRequest(r, &req); err != nil {
+		g.log.Error("Ошибка декодирования JSON:", err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	gameKeyPublic, err := g.gameUC.CreateBotGame(r.Context(), req, userID)
+	if err != nil {
+		g.log.Error("Ошибка создания партии против бота: ", err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, "Ошибка создания игры: "+err.Error())
+		return
+	}
+
+	g.log.Info("Новая партия против бота создана с ключом: " + gameKeyPublic)
+	httpresponse.WriteResponseWithStatus(w, http.StatusOK, game.GameCreateResponse{UniqueKey: gameKeyPublic})
+}
+
+// HandleBotLevels godoc
+// @Summary Уровни бота
+// @Description Возвращает названия уровней силы KataGo от слабого к сильному, из которых выбирается уровень партии против бота.
+// @Tags game
+// @Produce json
+// @Success 200 {array} string "Уровни бота"
+// @Failure 405 {object} httpresponse.ErrorResponse "Метод не разрешен"
+// @Router /botLevels [get]
+func (g *GameHandler) HandleBotLevels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		g.log.Error("Разрешен только метод GET")
+		httpresponse.WriteResponseWithStatus(w, http.StatusMethodNotAllowed, "Разрешен только метод GET")
+		return
+	}
+	httpresponse.WriteResponseWithStatus(w, http.StatusOK, bot.Levels())
+}
+
+// requestBotMove запрашивает у KataGo ход в позиции position, повторяя запрос при ошибках сервиса.
+func (g *GameHandler) requestBotMove(position game.BotPosition, strength bot.Strength) (game.Move, error) {
+	var err error
+	for attempt := 0; attempt < botRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(botRetryDelay)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), botMoveTimeout)
+		var move game.Move
+		move, _, err = katagoUC.GenMove(ctx, position, strength, g.katago)
+		cancel()
+		if err == nil || errors.Is(err, errs.ErrBotResigned) {
+			return move, err
+		}
+		g.log.Error("Ошибка запроса хода у KataGo:", err)
+	}
+	return game.Move{}, err
+}
+
+// botTurn запрашивает ход у KataGo, если в партии против бота его очередь. Запрос идёт
+// в отдельной горутине, а ответ применяет горутина хаба. В фазе подсчёта бот сразу
+// соглашается с пометкой мёртвых камней.
+func (h *hub) botTurn() {
+	color := h.game.BotColor()
+	if !h.owner || color == board.Empty || h.botThinking || h.game.Status == statuses.StatusCompleted {
+		return
+	}
+	if h.game.Status == statuses.StatusScoring {
+		h.botAcceptScore(color)
+		return
+	}
+	if h.game.WhoIsNext != color.String() || h.game.PlayerBlack == "" || h.game.PlayerWhite == "" {
+		return
+	}
+
+	strength, err := bot.ParseLevel(h.game.BotLevel)
+	if err != nil {
+		h.handler.log.Errorf("Неизвестный уровень бота %q, используется %s", h.game.BotLevel, bot.DefaultLevel)
+		strength, _ = bot.ParseLevel(bot.DefaultLevel)
+	}
+
+	h.botThinking = true
+	position := h.game.BotPosition()
+	ply := len(position.Moves)
+	go func() {
+		move, err := h.handler.requestBotMove(position, strength)
+		h.submit(func() { h.botMoved(ply, move, err) })
+	}()
+}
+
+// botMoved проверяет и записывает ход бота, сделанный в позиции после ply ходов,
+// и рассылает его игроку и зрителям.
+func (h *hub) botMoved(ply int, move game.Move, err error) {
+	h.botThinking = false
+	if !h.owner || len(h.game.Moves) != ply || h.game.Status == statuses.StatusCompleted {
+		h.botTurn()
+		return
+	}
+
+	action := game.GameAction{Type: game.ActionMove, Move: move}
+	if errors.Is(err, errs.ErrBotResigned) {
+		action = game.GameAction{Type: game.ActionResign}
+	} else if err != nil {
+		h.botUnavailable(err)
+		return
+	}
+
+	resp, err := h.handler.applyAction(context.Background(), h.game, game.BotPlayerID, action)
+	if err != nil {
+		h.botUnavailable(err)
+		return
+	}
+	h.botEvent(eventType(action.Type), resp)
+}
+
+// botAcceptScore соглашается с подсчётом от имени бота.
+func (h *hub) botAcceptScore(color board.Color) {
+	if h.game.ScoreAccepted[color.String()] {
+		return
+	}
+	resp, err := h.handler.applyAction(context.Background(), h.game, game.BotPlayerID, game.GameAction{Type: game.ActionAcceptScore})
+	if err != nil {
+		h.handler.log.Error("Бот не смог согласиться с подсчётом:", err)
+		return
+	}
+	h.botEvent(protocol.TypeGameState, resp)
+}
+
+// botEvent рассылает действие бота всем участникам партии. Если пас бота начал подсчёт очков,
+// бот сразу соглашается с ним.
+func (h *hub) botEvent(typ string, resp game.GameStateResponse) {
+	resp.Clock = clockState(h.game)
+	h.armFlagTimer()
+	h.broadcast(h.event(typ, resp))
+	switch resp.Status {
+	case statuses.StatusCompleted:
+		h.finish()
+	case statuses.StatusScoring:
+		h.botTurn()
+	}
+}
+
+// botUnavailable сообщает игроку, что бот не смог сделать ход. Запрос повторится,
+// когда игрок переподключится к партии.
+func (h *hub) botUnavailable(err error) {
+	h.handler.log.Error("Бот не сделал ход:", err)
+	h.broadcast(h.event(protocol.TypeNotice, protocol.Notice{
+		Code:    protocol.NoticeBotUnavailable,
+		Message: "Бот не смог сделать ход, переподключитесь к партии позже",
+		Color:   h.game.BotColor().String(),
+	}))
+}
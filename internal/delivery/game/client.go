@@ -0,0 +1,125 @@
+package game
+
+import (
+	"errors"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/protocol"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// clientBuffer сколько сообщений может ждать отправки медленному клиенту, прежде чем его отключат.
+	clientBuffer = 64
+	writeTimeout = 10 * time.Second
+)
+
+// client подключение игрока или зрителя. В сокет пишет только writePump,
+// читает только readPump, а send закрывает горутина хаба. У клиента другого экземпляра
+// нет сокета: сообщения ему уходят через канал партии.
+type client struct {
+	id     string
+	conn   *websocket.Conn
+	send   chan protocol.Envelope
+	userID string
+	color  board.Color // board.Empty у зрителя
+	closed bool        // send закрыт, изменяется только горутиной хаба
+	remote bool        // клиент подключён к другому экземпляру
+}
+
+func newClient(conn *websocket.Conn, userID string) *client {
+	return &client{
+		id:     uuid.New().String(),
+		conn:   conn,
+		send:   make(chan protocol.Envelope, clientBuffer),
+		userID: userID,
+	}
+}
+
+// writePump отправляет клиенту сообщения из send и пингует его. Когда send закрыт,
+// закрывает соединение, и readPump завершается ошибкой чтения.
+func (g *GameHandler) writePump(c *client) {
+	ticker := time.NewTicker(g.pingInterval())
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+	for {
+		select {
+		case env, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteJSON(env); err != nil {
+				g.log.Error("Ошибка отправки сообщения клиенту:", err)
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readPump читает сообщения клиента и передаёт их хабу, пока соединение живо.
+// Если pong не приходит за WS_PONG_TIMEOUT, чтение завершается по таймауту.
+func (g *GameHandler) readPump(h *hub, c *client) {
+	defer h.submit(func() { h.disconnect(c) })
+
+	timeout := g.pongTimeout()
+	c.conn.SetReadDeadline(time.Now().Add(timeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(timeout))
+	})
+
+	for {
+		env, err := readEnvelope(c.conn)
+		if errors.Is(err, protocol.ErrBadMessage) {
+			h.submit(func() { h.replyError(c, 0, err) })
+			continue
+		}
+		if err != nil {
+			return
+		}
+		if !h.submit(func() { h.dispatch(c, env) }) {
+			return
+		}
+	}
+}
+
+// send ставит сообщение в очередь клиента. Клиент, который не успевает забирать сообщения,
+// отключается. Вызывается горутиной хаба.
+func (h *hub) send(c *client, env protocol.Envelope) {
+	if c.closed {
+		return
+	}
+	if c.remote {
+		h.publish(relayMessage{Kind: relayDeliver, Client: c.id, Envelope: &env})
+		return
+	}
+	select {
+	case c.send <- env:
+	default:
+		h.handler.log.Error("Очередь сообщений клиента переполнена, соединение закрыто")
+		h.drop(c)
+	}
+}
+
+// drop закрывает очередь клиента, после чего writePump закрывает соединение.
+func (h *hub) drop(c *client) {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	if c.remote {
+		h.publish(relayMessage{Kind: relayDrop, Client: c.id})
+		return
+	}
+	close(c.send)
+}
@@ -2,33 +2,47 @@ package game
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"strconv"
-	"sync"
 	"team_exe/internal/adapters"
 	"team_exe/internal/bootstrap"
 	"team_exe/internal/delivery/auth"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/clock"
 	"team_exe/internal/domain/game"
+	"team_exe/internal/domain/matchmaking"
+	"team_exe/internal/domain/protocol"
+	"team_exe/internal/domain/rules"
+	errs "team_exe/internal/errors"
 	"team_exe/internal/httpresponse"
 	repo "team_exe/internal/repository"
+	"team_exe/internal/statuses"
 	gameuc "team_exe/internal/usecase/game"
+	matchmakinguc "team_exe/internal/usecase/matchmaking"
 	"team_exe/internal/utils"
+	katagoProto "team_exe/microservices/proto"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
 type GameHandler struct {
-	cfg          bootstrap.Config
-	log          *zap.SugaredLogger
-	gameUC       *gameuc.GameUseCase
-	mongoAdapter *adapters.AdapterMongo
-	redisAdapter *adapters.AdapterRedis
-	authHandler  *auth.AuthHandler
+	cfg           bootstrap.Config
+	log           *zap.SugaredLogger
+	gameUC        *gameuc.GameUseCase
+	matchmakingUC *matchmakinguc.MatchmakingUseCase
+	mongoAdapter  *adapters.AdapterMongo
+	redisAdapter  *adapters.AdapterRedis
+	authHandler   *auth.AuthHandler
+	relay         *repo.GameRelayRepository
+	katago        katagoProto.KatagoServiceClient
+	hubs          *hubRegistry
 }
 
 type FindGameInArchive struct {
@@ -43,19 +57,58 @@ type JsonOKResponse struct {
 	Text string `json:"text"`
 }
 
-var activeGames = make(map[string]*game.Game)
-var activeGamesMu sync.RWMutex
-
 // NewGameHandler создаёт новый обработчик игр.
-func NewGameHandler(cfg bootstrap.Config, log *zap.SugaredLogger, mongoAdapter *adapters.AdapterMongo, redisAdapter *adapters.AdapterRedis, authHandler *auth.AuthHandler) *GameHandler {
+func NewGameHandler(cfg bootstrap.Config, log *zap.SugaredLogger, mongoAdapter *adapters.AdapterMongo, redisAdapter *adapters.AdapterRedis, authHandler *auth.AuthHandler, katago katagoProto.KatagoServiceClient) *GameHandler {
+	gameUC := gameuc.NewGameUseCase(repo.NewGameRepository(cfg, log, redisAdapter.GetClient(), mongoAdapter.Database), authHandler.UsecaseHandler)
+	window := matchmaking.Window{
+		Base:      cfg.MatchmakingWindowBase,
+		PerMinute: cfg.MatchmakingWindowPerMinute,
+		Max:       cfg.MatchmakingWindowMax,
+	}
 	return &GameHandler{
-		cfg:         cfg,
-		log:         log,
-		gameUC:      gameuc.NewGameUseCase(repo.NewGameRepository(cfg, log, redisAdapter.GetClient(), mongoAdapter.Database), authHandler.UsecaseHandler),
-		authHandler: authHandler,
+		cfg:           cfg,
+		log:           log,
+		gameUC:        gameUC,
+		matchmakingUC: matchmakinguc.NewMatchmakingUseCase(repo.NewMatchmakingRepository(log, redisAdapter.GetClient()), gameUC, authHandler.UsecaseHandler, window),
+		authHandler:   authHandler,
+		relay:         repo.NewGameRelayRepository(log, redisAdapter.GetClient()),
+		katago:        katago,
+		hubs:          newHubRegistry(),
 	}
 }
 
+// RestoreActiveGames поднимает в памяти незавершённые партии после перезапуска сервера,
+// чтобы игроки могли переподключиться, а часы продолжили идти.
+func (g *GameHandler) RestoreActiveGames(ctx context.Context) {
+	games, err := g.gameUC.GetUnfinishedGames(ctx)
+	if err != nil {
+		g.log.Error("Ошибка загрузки незавершённых партий:", err)
+		return
+	}
+
+	for i := range games {
+		play := &games[i]
+		var restoreErr error
+		h, opened := g.hubs.open(g, play, func(play *game.Game) {
+			restoreErr = g.gameUC.RestoreLiveGame(play)
+		})
+		if !opened {
+			continue
+		}
+		if restoreErr != nil {
+			g.log.Errorf("Не удалось восстановить партию %s: %v", play.GameKeySecret, restoreErr)
+		}
+		h.call(func() {
+			h.armFlagTimer()
+			// после перезапуска никто не подключён: не вернувшимся игрокам партия будет присуждена
+			h.armAbandonTimer(board.Black)
+			h.armAbandonTimer(board.White)
+			h.botTurn()
+		})
+	}
+	g.log.Infof("Восстановлено партий: %d", g.hubs.count())
+}
+
 // HandleGetGameByPublicKey godoc
 // @Summary Получить игру по публичному ключу
 // @Description Возвращает подробную информацию об игре по публичному ключу, переданному в теле запроса.
@@ -86,19 +139,23 @@ func (g *GameHandler) HandleGetGameByPublicKey(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	gameByID, err := g.gameUC.GetGameByPublicKey(r.Context(), gameData.GamePublicKey)
+	gameByID, err := g.gameUC.GetGameInfoByPublicKey(r.Context(), gameData.GamePublicKey)
 	if err != nil {
 		httpresponse.WriteResponseWithStatus(w, http.StatusInternalServerError,
 			httpresponse.ErrorResponse{ErrorDescription: err.Error()})
 		return
 	}
 
+	if h := g.hubs.get(gameByID.Game.GameKeySecret); h != nil {
+		h.call(func() { gameByID.Spectators = len(h.spectators) })
+	}
+
 	httpresponse.WriteResponseWithStatus(w, http.StatusOK, gameByID)
 }
 
 // HandleNewGame godoc
 // @Summary Создать новую игру
-// @Description Создает новую игру с указанными параметрами (размер доски, коми и роль). Требуется авторизация через cookie.
+// @Description Создает новую игру с указанными параметрами (размер доски, коми, правила, фора и роль). Если коми не указано, оно выбирается по правилам и форе. Требуется авторизация через cookie.
 // @Tags game
 // @Accept json
 // @Produce json
@@ -121,9 +178,21 @@ func (g *GameHandler) HandleNewGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if newGameRequest.BoardSize == 0 || newGameRequest.Komi == 0 {
-		g.log.Error("Запрос на создание игры не содержит размер доски или коми")
-		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, "Запрос не содержит размер доски или коми")
+	if newGameRequest.BoardSize == 0 {
+		g.log.Error("Запрос на создание игры не содержит размер доски")
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, "Запрос не содержит размер доски")
+		return
+	}
+
+	if newGameRequest.BoardSize < 2 || newGameRequest.BoardSize > board.MaxSize {
+		g.log.Error("Недопустимый размер доски: ", newGameRequest.BoardSize)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, "Недопустимый размер доски")
+		return
+	}
+
+	if _, err := rules.Parse(newGameRequest.Rules); err != nil {
+		g.log.Error("Неизвестные правила: ", newGameRequest.Rules)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, "Неизвестные правила: "+newGameRequest.Rules)
 		return
 	}
 
@@ -148,21 +217,13 @@ func (g *GameHandler) HandleNewGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err, gameKeyPublic, gameKeySecret := g.gameUC.CreateGame(ctx, newGameRequest, userID)
+	err, gameKeyPublic, _ := g.gameUC.CreateGame(ctx, newGameRequest, userID)
 	if err != nil {
 		g.log.Error(err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, "Ошибка создания игры: "+err.Error())
 		return
 	}
 
-	newGame, err := g.gameUC.GetGameBySecreteKey(ctx, gameKeySecret)
-	if err != nil {
-		g.log.Error("Не удалось получить игру после создания:", err)
-	} else {
-		activeGamesMu.Lock()
-		activeGames[gameKeySecret] = &newGame
-		activeGamesMu.Unlock()
-	}
-
 	resp := game.GameCreateResponse{
 		UniqueKey: gameKeyPublic,
 	}
@@ -172,7 +233,7 @@ func (g *GameHandler) HandleNewGame(w http.ResponseWriter, r *http.Request) {
 
 // LeaveGame godoc
 // @Summary Покинуть игру
-// @Description Позволяет пользователю покинуть игру, передав публичный ключ игры. Требуется авторизация через cookie.
+// @Description Позволяет пользователю покинуть игру, передав публичный ключ игры. Начатую партию пользователь покидает сдачей: победа присуждается сопернику и учитывается в статистике обоих игроков. Требуется авторизация через cookie.
 // @Tags game
 // @Accept json
 // @Produce json
@@ -208,6 +269,24 @@ func (g *GameHandler) LeaveGame(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
+	// партию, которую ведёт хаб этого экземпляра, игрок сдаёт через хаб,
+	// чтобы соперник и зрители сразу получили результат
+	if play, err := g.gameUC.GetGameByPublicKey(ctx, gameLeaveRequest.GameKeyPublic); err == nil {
+		if h := g.hubs.get(play.GameKeySecret); h != nil {
+			var resigned bool
+			var resignErr error
+			if h.call(func() { resigned, resignErr = h.resignLeaver(userID) }) && resigned {
+				if resignErr != nil {
+					g.log.Error(resignErr)
+					httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, resignErr.Error())
+					return
+				}
+				httpresponse.WriteResponseWithStatus(w, http.StatusOK, "Пользователь успешно покинул игру")
+				return
+			}
+		}
+	}
+
 	ok, err := g.gameUC.LeaveGame(ctx, gameLeaveRequest.GameKeyPublic, userID)
 	if err != nil || !ok {
 		g.log.Error(err)
@@ -256,6 +335,11 @@ func (g *GameHandler) HandleJoinGame(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
+	if gameJoinRequest.Role == game.RoleSpectator {
+		g.joinAsSpectator(w, r, gameJoinRequest.GameKeyPublic)
+		return
+	}
+
 	isAlreadyInGame, err := g.gameUC.HasUserActiveGamesByUserId(ctx, userID)
 	if err != nil {
 		g.log.Error(err)
@@ -288,35 +372,44 @@ func (g *GameHandler) HandleJoinGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	activeGamesMu.Lock()
-	if existingGame, ok := activeGames[play.GameKeySecret]; ok {
-		if play.PlayerBlack == userID {
-			existingGame.PlayerBlack = userID
-		} else if play.PlayerWhite == userID {
-			existingGame.PlayerWhite = userID
-		}
-	} else {
-		retrievedGame, err := g.gameUC.GetGameByPublicKey(ctx, gameJoinRequest.GameKeyPublic)
-		if err == nil {
-			activeGames[play.GameKeySecret] = &retrievedGame
-		}
-	}
-	activeGamesMu.Unlock()
-
 	resp := JsonOKResponse{
 		Text: "Пользователь успешно присоединился",
 	}
 	httpresponse.WriteResponseWithStatus(w, http.StatusOK, resp)
 }
 
+// joinAsSpectator проверяет, что за партией можно наблюдать. Зритель не становится участником
+// партии, он подключается к ней через /startGame.
+func (g *GameHandler) joinAsSpectator(w http.ResponseWriter, r *http.Request, gameKeyPublic string) {
+	play, err := g.gameUC.GetGameByPublicKey(r.Context(), gameKeyPublic)
+	if err != nil || play.GameKeySecret == "" {
+		g.log.Error("Игра не найдена! Id: "+gameKeyPublic, err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, "Игра не найдена! Id: "+gameKeyPublic)
+		return
+	}
+
+	spectators := 0
+	if h := g.hubs.get(play.GameKeySecret); h != nil {
+		h.call(func() { spectators = len(h.spectators) })
+	}
+	if err = g.gameUC.CheckSpectator(&play, spectators); err != nil {
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	httpresponse.WriteResponseWithStatus(w, http.StatusOK, JsonOKResponse{Text: "Можно наблюдать за игрой"})
+}
+
 // HandleStartGame godoc
 // @Summary Запуск игры через WebSocket
-// @Description Обновляет HTTP-соединение до WebSocket для обмена ходами в режиме реального времени.
+// @Description Обновляет HTTP-соединение до WebSocket для обмена ходами в режиме реального времени. Пользователь, не играющий в партии, подключается к публичной партии зрителем: он получает текущее состояние и все последующие ходы, но не может ходить.
 // @Tags game
 // @Accept json
 // @Produce json
 // @Param game_id query string true "Идентификатор игры"
-// @Success 200 {object} game.GameStateResponse "Обновление состояния игры в реальном времени"
+// @Param version query int true "Версия протокола сокета, устаревшие клиенты получают ошибку upgrade_required"
+// @Param since_seq query int false "Номер последнего полученного события: сервер дошлёт только более поздние события, иначе пришлёт полное состояние"
+// @Success 200 {object} protocol.Envelope "Сообщения {type, seq, payload}: ack, error, notice, clock и события партии move, pass, resign, chat, game-state"
 // @Failure 400 {object} httpresponse.ErrorResponse "Неверный запрос"
 // @Router /startGame [get]
 func (g *GameHandler) HandleStartGame(w http.ResponseWriter, r *http.Request) {
@@ -336,79 +429,164 @@ func (g *GameHandler) HandleStartGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	retrievedGame, err := g.gameUC.GetGameByPublicKey(ctx, gameID)
-	if err != nil {
-		g.log.Error(err)
-		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, err.Error())
+	if _, err = protocol.Negotiate(r.URL.Query().Get("version")); err != nil {
+		g.log.Error("Клиент с неподдерживаемой версией протокола:", err)
+		g.rejectConn(conn, err)
 		return
 	}
 
-	activeGamesMu.Lock()
-	ag, ok := activeGames[retrievedGame.GameKeySecret]
-	if !ok {
-		activeGames[retrievedGame.GameKeySecret] = &retrievedGame
-		ag = &retrievedGame
-	}
-	activeGamesMu.Unlock()
-
-	var playerWS **websocket.Conn
-	var opponentWS **websocket.Conn
-	switch playerID {
-	case ag.PlayerBlack:
-		playerWS, opponentWS = &ag.PlayerBlackWS, &ag.PlayerWhiteWS
-	case ag.PlayerWhite:
-		playerWS, opponentWS = &ag.PlayerWhiteWS, &ag.PlayerBlackWS
-	default:
-		g.log.Error("Неизвестный ID игрока:", playerID)
-		fmt.Println(ag)
-		conn.Close()
+	retrievedGame, err := g.gameUC.GetGameByPublicKey(ctx, gameID)
+	if err != nil {
+		g.log.Error(err)
+		g.rejectConn(conn, err)
 		return
 	}
 
-	if *playerWS != nil {
-		(*playerWS).WriteMessage(websocket.TextMessage, []byte("Вы были отключены, создано новое соединение."))
-		(*playerWS).Close()
-	}
-	*playerWS = conn
-
-	defer func() {
-		conn.Close()
-		activeGamesMu.Lock()
-		if *playerWS == conn {
-			*playerWS = nil
-		}
-		activeGamesMu.Unlock()
-	}()
-
+	c := newClient(conn, playerID)
+	go g.writePump(c)
+	sinceSeq := sinceSeqParam(r)
+	play := &retrievedGame
 	for {
-		var move game.Move
-		if err = conn.ReadJSON(&move); err != nil {
-			g.log.Error("Ошибка чтения JSON из WebSocket:", err)
+		h, _ := g.hubs.open(g, play, func(play *game.Game) {
+			if err := g.gameUC.RestoreLiveGame(play); err != nil {
+				g.log.Error("Ошибка восстановления партии:", err)
+			}
+		})
+		// хаб мог остановиться между поиском и подключением, тогда запускаем новый
+		if h.call(func() { h.connect(c, sinceSeq) }) {
+			g.readPump(h, c)
 			return
 		}
-		g.log.Info("Получен ход:", move)
-		sgfString, err := g.gameUC.AddMoveToGameSgf(ag.GameKeySecret, move)
+		reloaded, err := g.gameUC.GetGameByPublicKey(ctx, gameID)
 		if err != nil {
 			g.log.Error(err)
-			conn.WriteMessage(websocket.TextMessage, []byte(err.Error()))
-			continue
+			close(c.send)
+			return
+		}
+		play = &reloaded
+	}
+}
+
+// applyAction выполняет действие игрока и формирует сообщение о новом состоянии партии.
+// Статус заполняется только тогда, когда действие переводит партию в новую стадию.
+func (g *GameHandler) applyAction(ctx context.Context, ag *game.Game, playerID string, action game.GameAction) (game.GameStateResponse, error) {
+	switch action.Type {
+	case "", game.ActionMove:
+		// ход "pass" делается как пас и тоже может начать подсчёт очков
+		move, sgfString, err := g.gameUC.PlayMove(ctx, ag, playerID, action.Move)
+		if err != nil {
+			return game.GameStateResponse{}, err
 		}
-		resp := game.GameStateResponse{
-			Move: move,
-			SGF:  sgfString,
+		return moveState(ag, move, sgfString), nil
+	case game.ActionPass:
+		move, sgfString, err := g.gameUC.Pass(ctx, ag, playerID)
+		if err != nil {
+			return game.GameStateResponse{}, err
 		}
-		if *opponentWS != nil {
-			if err := (*opponentWS).WriteJSON(resp); err != nil {
-				g.log.Error("Ошибка отправки сообщения оппоненту:", err)
-				(*opponentWS).Close()
-				activeGamesMu.Lock()
-				*opponentWS = nil
-				activeGamesMu.Unlock()
+		return moveState(ag, move, sgfString), nil
+	case game.ActionResign:
+		sgfString, err := g.gameUC.Resign(ctx, ag, playerID)
+		if err != nil {
+			if ag.Status != statuses.StatusCompleted {
+				return game.GameStateResponse{}, err
 			}
-		} else {
-			conn.WriteMessage(websocket.TextMessage, []byte("Оппонент не подключён"))
+			// партия уже завершена, не удалось только обновить статистику
+			g.log.Error("Ошибка обновления статистики:", err)
+		}
+		return game.GameStateResponse{
+			SGF:    sgfString,
+			Status: ag.Status,
+			Result: ag.Result.SgfString(),
+		}, nil
+	case game.ActionToggleDead:
+		if err := g.gameUC.ToggleDeadStones(ctx, ag, playerID, action.Coordinates); err != nil {
+			return game.GameStateResponse{}, err
+		}
+		return scoringState(ag), nil
+	case game.ActionAcceptScore:
+		score, sgfString, err := g.gameUC.AcceptScore(ctx, ag, playerID)
+		if err != nil {
+			if ag.Status != statuses.StatusCompleted {
+				return game.GameStateResponse{}, err
+			}
+			g.log.Error("Ошибка обновления статистики:", err)
+		}
+		if score == nil {
+			return scoringState(ag), nil
+		}
+		return game.GameStateResponse{
+			SGF:    sgfString,
+			Status: ag.Status,
+			Result: ag.Result.SgfString(),
+			Score:  score,
+		}, nil
+	case game.ActionUndoRequest:
+		if err := g.gameUC.RequestUndo(ag, playerID); err != nil {
+			return game.GameStateResponse{}, err
+		}
+		return game.GameStateResponse{Event: game.EventUndoRequested}, nil
+	case game.ActionUndoAccept, game.ActionUndoDecline:
+		accept := action.Type == game.ActionUndoAccept
+		sgfString, err := g.gameUC.AnswerUndo(ctx, ag, playerID, accept)
+		if err != nil {
+			return game.GameStateResponse{}, err
+		}
+		if !accept {
+			return game.GameStateResponse{Event: game.EventUndoDeclined}, nil
+		}
+		return game.GameStateResponse{
+			Event:     game.EventUndoAccepted,
+			SGF:       sgfString,
+			Moves:     ag.Moves,
+			WhoIsNext: ag.WhoIsNext,
+		}, nil
+	case game.ActionChat:
+		message, err := g.gameUC.PostChat(ctx, ag, playerID, action.Channel, action.Text)
+		if err != nil {
+			return game.GameStateResponse{}, err
+		}
+		return game.GameStateResponse{Event: game.EventChat, Chat: &message}, nil
+	}
+	return game.GameStateResponse{}, errs.ErrUnknownAction
+}
+
+// clockState возвращает состояние часов партии или nil, если время не ограничено.
+func clockState(ag *game.Game) *clock.State {
+	if ag.Clock == nil {
+		return nil
+	}
+	state := ag.Clock.State(time.Now())
+	return &state
+}
+
+// notifiesBoth сообщает, что ответ касается обоих игроков: смена стадии партии, откат позиции
+// или сообщение чата.
+func notifiesBoth(resp game.GameStateResponse) bool {
+	return resp.Status != "" || resp.Event == game.EventUndoAccepted || resp.Event == game.EventChat
+}
+
+// scoringState описывает текущую пометку мёртвых камней и согласие игроков с ней.
+// moveState формирует сообщение о ходе. Статус передаётся, если после второго паса подряд
+// партия перешла к подсчёту очков.
+func moveState(ag *game.Game, move game.Move, sgfString string) game.GameStateResponse {
+	resp := game.GameStateResponse{Move: move, SGF: sgfString}
+	if ag.Status == statuses.StatusScoring {
+		resp.Status = ag.Status
+	}
+	return resp
+}
+
+func scoringState(ag *game.Game) game.GameStateResponse {
+	resp := game.GameStateResponse{Status: ag.Status}
+	for _, p := range ag.Board.DeadStones() {
+		resp.DeadStones = append(resp.DeadStones, p.SGF())
+	}
+	for color, accepted := range ag.ScoreAccepted {
+		if accepted {
+			resp.ScoreAccepted = append(resp.ScoreAccepted, color)
 		}
 	}
+	return resp
 }
 
 // HandleGetArchivePaginator godoc
@@ -446,7 +624,7 @@ func (g *GameHandler) HandleGetArchivePaginator(w http.ResponseWriter, r *http.R
 		yearNum, err = strconv.Atoi(year)
 		if err != nil {
 			g.log.Error(err)
-			httpresponse.WriteResponseWithStatus(w, 400, fmt.Errorf("ошибка преобразования года: "+err.Error()))
+			httpresponse.WriteResponseWithStatus(w, 400, fmt.Errorf("ошибка преобразования года: %w", err))
 			return
 		}
 	}
@@ -456,7 +634,7 @@ func (g *GameHandler) HandleGetArchivePaginator(w http.ResponseWriter, r *http.R
 		pageNum, err = strconv.Atoi(page)
 		if err != nil {
 			g.log.Error(err)
-			httpresponse.WriteResponseWithStatus(w, 400, fmt.Errorf("ошибка преобразования номера страницы: "+err.Error()))
+			httpresponse.WriteResponseWithStatus(w, 400, fmt.Errorf("ошибка преобразования номера страницы: %w", err))
 			return
 		}
 	}
@@ -465,13 +643,94 @@ func (g *GameHandler) HandleGetArchivePaginator(w http.ResponseWriter, r *http.R
 	resp, err := g.gameUC.GetArchiveOfGames(ctx, pageNum, yearNum, name)
 	if err != nil {
 		g.log.Error(err)
-		httpresponse.WriteResponseWithStatus(w, 400, fmt.Errorf("ошибка получения архива: "+err.Error()))
+		httpresponse.WriteResponseWithStatus(w, 400, fmt.Errorf("ошибка получения архива: %w", err))
 		return
 	}
 
 	httpresponse.WriteResponseWithStatus(w, http.StatusOK, resp)
 }
 
+// HandleSuggestHandicap godoc
+// @Summary Предложить фору и коми
+// @Description По разнице рангов текущего пользователя и соперника предлагает число камней форы, коми и цвет создателя игры. Требуется авторизация через cookie.
+// @Tags game
+// @Accept json
+// @Produce json
+// @Param request body game.HandicapSuggestionRequest true "Соперник, размер доски и правила"
+// @Success 200 {object} game.HandicapSuggestion "Предложенные условия партии"
+// @Failure 400 {object} httpresponse.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} httpresponse.ErrorResponse "Пользователь не авторизован"
+// @Failure 405 {object} httpresponse.ErrorResponse "Метод не разрешен"
+// @Router /suggestHandicap [post]
+func (g *GameHandler) HandleSuggestHandicap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		g.log.Error("Разрешен только метод POST")
+		httpresponse.WriteResponseWithStatus(w, http.StatusMethodNotAllowed, "Разрешен только метод POST")
+		return
+	}
+
+	userID := g.authHandler.GetUserID(w, r)
+	if userID == "" {
+		g.log.Error("UserID не найден в cookie")
+		httpresponse.WriteResponseWithStatus(w, http.StatusUnauthorized, "UserID не найден в cookie")
+		return
+	}
+
+	var req game.HandicapSuggestionRequest
+	if err := utils.DecodeJSONRequest(r, &req); err != nil {
+		g.log.Error("Ошибка декодирования JSON:", err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.OpponentID == "" || req.BoardSize < 2 || req.BoardSize > board.MaxSize {
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, "Запрос должен содержать соперника и допустимый размер доски")
+		return
+	}
+
+	suggestion, err := g.gameUC.SuggestHandicap(r.Context(), userID, req)
+	if err != nil {
+		g.log.Error("Ошибка подбора форы: ", err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	httpresponse.WriteResponseWithStatus(w, http.StatusOK, suggestion)
+}
+
+// HandleRecomputeStatistics godoc
+// @Summary Пересчитать статистику
+// @Description Пересчитывает статистику текущего пользователя по всем его завершённым партиям, если счётчики разошлись с историей. Требуется авторизация через cookie.
+// @Tags game
+// @Produce json
+// @Success 200 {object} user.UserStatistic "Пересчитанная статистика"
+// @Failure 401 {object} httpresponse.ErrorResponse "Пользователь не авторизован"
+// @Failure 405 {object} httpresponse.ErrorResponse "Метод не разрешен"
+// @Failure 500 {object} httpresponse.ErrorResponse "Ошибка пересчёта статистики"
+// @Router /recomputeStatistics [post]
+func (g *GameHandler) HandleRecomputeStatistics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		g.log.Error("Разрешен только метод POST")
+		httpresponse.WriteResponseWithStatus(w, http.StatusMethodNotAllowed, "Разрешен только метод POST")
+		return
+	}
+
+	userID := g.authHandler.GetUserID(w, r)
+	if userID == "" {
+		g.log.Error("UserID не найден в cookie")
+		httpresponse.WriteResponseWithStatus(w, http.StatusUnauthorized, "UserID не найден в cookie")
+		return
+	}
+
+	statistic, err := g.gameUC.RecomputeStatistics(r.Context(), userID)
+	if err != nil {
+		g.log.Error("Ошибка пересчёта статистики: ", err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusInternalServerError, "ошибка пересчёта статистики")
+		return
+	}
+
+	httpresponse.WriteResponseWithStatus(w, http.StatusOK, statistic)
+}
+
 // HandleGetYearsInArchive godoc
 // @Summary Получить массив годов из архива
 // @Description Возвращает отсортированный массив годов (int), доступных в архиве чужих партий.
@@ -536,7 +795,7 @@ func (g *GameHandler) HandleGetNamesInArchive(w http.ResponseWriter, r *http.Req
 	pageNumInt, err := strconv.Atoi(pageNum)
 	if err != nil {
 		g.log.Error(err)
-		httpresponse.WriteResponseWithStatus(w, 400, fmt.Errorf("ошибка преобразования года: "+err.Error()))
+		httpresponse.WriteResponseWithStatus(w, 400, fmt.Errorf("ошибка преобразования года: %w", err))
 		return
 	}
 
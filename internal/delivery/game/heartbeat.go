@@ -0,0 +1,131 @@
+package game
+
+import (
+	"context"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/game"
+	"team_exe/internal/domain/protocol"
+	"team_exe/internal/statuses"
+	"time"
+)
+
+// Значения по умолчанию для сердцебиения сокета и присуждения покинутых партий.
+const (
+	defaultPingInterval    = 20 * time.Second
+	defaultPongTimeout     = 60 * time.Second
+	defaultAbandonGrace    = 2 * time.Minute
+	defaultAbandonMinMoves = 10
+)
+
+// pingInterval период, с которым клиенту отправляется ping.
+func (g *GameHandler) pingInterval() time.Duration {
+	if g.cfg.WsPingInterval > 0 {
+		return g.cfg.WsPingInterval
+	}
+	return defaultPingInterval
+}
+
+// pongTimeout время, за которое клиент должен ответить pong, иначе соединение закрывается.
+func (g *GameHandler) pongTimeout() time.Duration {
+	if g.cfg.WsPongTimeout > 0 {
+		return g.cfg.WsPongTimeout
+	}
+	return defaultPongTimeout
+}
+
+// playerConnected снимает таймер присуждения и сообщает сопернику и зрителям,
+// что игрок вернулся.
+func (h *hub) playerConnected(color board.Color) {
+	timer, waiting := h.abandonTimers[color]
+	if !waiting {
+		return
+	}
+	timer.Stop()
+	delete(h.abandonTimers, color)
+	h.notifyPresence(color, protocol.Notice{
+		Code:    protocol.NoticeReconnected,
+		Message: "Соперник вернулся в игру",
+		Color:   color.String(),
+	})
+}
+
+// playerDisconnected сообщает сопернику и зрителям, что игрок отключился, и запускает таймер,
+// по которому партия будет присуждена, если игрок не вернётся.
+func (h *hub) playerDisconnected(color board.Color) {
+	if h.game.Status == statuses.StatusCompleted {
+		return
+	}
+	h.notifyPresence(color, protocol.Notice{
+		Code:    protocol.NoticeDisconnected,
+		Message: "Соперник отключился",
+		Color:   color.String(),
+	})
+	h.armAbandonTimer(color)
+}
+
+// armAbandonTimer запускает таймер присуждения партии игроку цвета color, если партия идёт.
+func (h *hub) armAbandonTimer(color board.Color) {
+	if timer, ok := h.abandonTimers[color]; ok {
+		timer.Stop()
+		delete(h.abandonTimers, color)
+	}
+	if !h.owner || h.game.PlayerBlack == "" || h.game.PlayerWhite == "" || h.game.BotColor() == color {
+		return
+	}
+
+	grace := h.handler.cfg.AbandonGrace
+	if grace <= 0 {
+		grace = defaultAbandonGrace
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(grace, func() {
+		h.submit(func() {
+			if h.abandonTimers[color] != timer {
+				return
+			}
+			delete(h.abandonTimers, color)
+			if h.players[color] == nil {
+				h.abandon(color)
+			}
+		})
+	})
+	h.abandonTimers[color] = timer
+}
+
+// abandon присуждает партию, которую покинул игрок цвета color, и сообщает итог оставшимся.
+func (h *hub) abandon(color board.Color) {
+	minMoves := h.handler.cfg.AbandonMinMoves
+	if minMoves <= 0 {
+		minMoves = defaultAbandonMinMoves
+	}
+	opponentOnline := h.connected(color.Opponent())
+	sgfString, err := h.handler.gameUC.Abandon(context.Background(), h.game, color, opponentOnline, minMoves)
+	if err != nil {
+		h.handler.log.Error("Ошибка присуждения покинутой партии:", err)
+	}
+	if h.game.Result == nil {
+		return
+	}
+	h.handler.log.Infof("Партия %s завершена без игрока %s: %s", h.game.GameKeyPublic, color, h.game.Result.SgfString())
+
+	h.broadcast(h.event(protocol.TypeGameState, game.GameStateResponse{
+		SGF:    sgfString,
+		Status: h.game.Status,
+		Result: h.game.Result.SgfString(),
+	}))
+	h.finish()
+}
+
+// connected сообщает, что игрок цвета color за доской. Бот всегда за доской.
+func (h *hub) connected(color board.Color) bool {
+	return h.players[color] != nil || h.game.BotColor() == color
+}
+
+// notifyPresence рассылает уведомление о подключении игрока его сопернику и зрителям.
+func (h *hub) notifyPresence(color board.Color, notice protocol.Notice) {
+	event := h.event(protocol.TypeNotice, notice)
+	if c, ok := h.players[color.Opponent()]; ok {
+		h.send(c, event)
+	}
+	h.notifySpectators(event)
+}
@@ -0,0 +1,351 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/game"
+	"team_exe/internal/domain/protocol"
+	"team_exe/internal/statuses"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// hub ведёт одну живую партию на этом экземпляре сервера. Состоянием партии, клиентами
+// и таймерами владеет только горутина run: остальные горутины передают ей команды через commands.
+// Ходы проверяет только хаб экземпляра, арендовавшего партию, остальные пересылают ему
+// сообщения своих клиентов через канал партии в Redis.
+type hub struct {
+	handler  *GameHandler
+	game     *game.Game
+	commands chan func()
+	done     chan struct{}
+
+	token       string    // метка хаба в аренде и канале партии
+	owner       bool      // хаб арендует партию и проверяет ходы
+	leasedAt    time.Time // когда аренда партии последний раз подтвердилась
+	unsubscribe func()
+	clients     map[string]*client // клиенты этого экземпляра по идентификатору
+	proxies     map[string]*client // клиенты других экземпляров, только у владельца
+	lastSeq     int64              // последний номер события, полученный от владельца
+	botThinking bool               // ход запрошен у KataGo
+
+	players       map[board.Color]*client
+	spectators    map[*client]struct{}
+	feed          protocol.Feed
+	flagTimer     *time.Timer
+	abandonTimers map[board.Color]*time.Timer
+}
+
+func newHub(handler *GameHandler, play *game.Game) *hub {
+	return &hub{
+		handler:       handler,
+		game:          play,
+		commands:      make(chan func()),
+		done:          make(chan struct{}),
+		token:         uuid.New().String(),
+		clients:       make(map[string]*client),
+		proxies:       make(map[string]*client),
+		players:       make(map[board.Color]*client),
+		spectators:    make(map[*client]struct{}),
+		abandonTimers: make(map[board.Color]*time.Timer),
+	}
+}
+
+// run выполняет команды по одной, пока хаб не станет не нужен.
+func (h *hub) run() {
+	h.startRelay()
+	defer func() {
+		h.handler.hubs.remove(h.game.GameKeySecret, h)
+		h.stopTimers()
+		close(h.done)
+		h.stopRelay()
+	}()
+	for cmd := range h.commands {
+		cmd()
+		if h.idle() {
+			return
+		}
+	}
+}
+
+// idle сообщает, что хаб можно остановить: у экземпляра нет клиентов партии, а у владельца
+// нет и клиентов других экземпляров. Незавершённую партию владелец держит, пока взведён таймер
+// флага или присуждения партии, а партию, ждущую соперника или брошенную до начала, отпускает сразу.
+// Аренда остановленного хаба снимается в stopRelay.
+func (h *hub) idle() bool {
+	if len(h.clients) > 0 {
+		return false
+	}
+	if !h.owner {
+		return true
+	}
+	if len(h.players) > 0 || len(h.spectators) > 0 {
+		return false
+	}
+	return h.game.Status == statuses.StatusCompleted || (h.flagTimer == nil && len(h.abandonTimers) == 0)
+}
+
+// submit передаёт команду горутине партии. Возвращает false, если хаб уже остановлен.
+func (h *hub) submit(cmd func()) bool {
+	select {
+	case h.commands <- cmd:
+		return true
+	case <-h.done:
+		return false
+	}
+}
+
+// call выполняет команду в горутине партии и дожидается её завершения.
+func (h *hub) call(cmd func()) bool {
+	finished := make(chan struct{})
+	if !h.submit(func() { cmd(); close(finished) }) {
+		return false
+	}
+	<-finished
+	return true
+}
+
+// hubRegistry хабы живых партий по секретному ключу.
+type hubRegistry struct {
+	mu   sync.Mutex
+	hubs map[string]*hub
+}
+
+func newHubRegistry() *hubRegistry {
+	return &hubRegistry{hubs: make(map[string]*hub)}
+}
+
+// get возвращает хаб партии или nil, если партия сейчас не ведётся.
+func (r *hubRegistry) get(key string) *hub {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hubs[key]
+}
+
+// open возвращает хаб партии, запуская новый, если его ещё нет. Перед запуском
+// хаба вызывается prepare, чтобы восстановить доску и часы.
+func (r *hubRegistry) open(handler *GameHandler, play *game.Game, prepare func(*game.Game)) (*hub, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if h, ok := r.hubs[play.GameKeySecret]; ok {
+		return h, false
+	}
+	prepare(play)
+	h := newHub(handler, play)
+	r.hubs[play.GameKeySecret] = h
+	go h.run()
+	return h, true
+}
+
+// remove забывает хаб, если он всё ещё зарегистрирован под ключом.
+func (r *hubRegistry) remove(key string, h *hub) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hubs[key] == h {
+		delete(r.hubs, key)
+	}
+}
+
+// count возвращает число ведущихся партий.
+func (r *hubRegistry) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.hubs)
+}
+
+// join подключает клиента к партии игроком или зрителем и отправляет ему состояние партии.
+func (h *hub) join(c *client, sinceSeq *int64) {
+	if c.userID != h.game.PlayerBlack && c.userID != h.game.PlayerWhite {
+		h.refreshPlayers()
+	}
+	switch c.userID {
+	case h.game.PlayerBlack:
+		c.color = board.Black
+	case h.game.PlayerWhite:
+		c.color = board.White
+	default:
+		h.joinSpectator(c, sinceSeq)
+		return
+	}
+
+	if old, ok := h.players[c.color]; ok {
+		h.reply(old, protocol.TypeNotice, protocol.Notice{Code: protocol.NoticeReplaced, Message: "Вы были отключены, создано новое соединение."})
+		h.drop(old)
+	}
+	h.players[c.color] = c
+	h.playerConnected(c.color)
+	h.handler.gameUC.StartClock(h.game, time.Now())
+	h.armFlagTimer()
+	h.resync(c, sinceSeq)
+	h.botTurn()
+}
+
+// leave отключает клиента. Уход игрока запускает таймер присуждения партии.
+func (h *hub) leave(c *client) {
+	h.drop(c)
+	if c.color == board.Empty {
+		if _, ok := h.spectators[c]; ok {
+			delete(h.spectators, c)
+			h.spectatorsChanged()
+		}
+		return
+	}
+	if h.players[c.color] == c {
+		delete(h.players, c.color)
+		h.playerDisconnected(c.color)
+	}
+}
+
+// handle обрабатывает сообщение клиента.
+func (h *hub) handle(c *client, env protocol.Envelope) {
+	switch env.Type {
+	case protocol.TypeClock:
+		h.reply(c, protocol.TypeClock, clockState(h.game))
+		return
+	case protocol.TypeResync:
+		h.handleResync(c, env)
+		return
+	}
+	if c.color == board.Empty {
+		h.handleSpectator(c, env)
+		return
+	}
+
+	action, err := decodeAction(env)
+	if err != nil {
+		h.replyError(c, env.Seq, err)
+		return
+	}
+	h.handler.log.Info("Получено действие:", action)
+
+	ctx := context.Background()
+	if h.flagFall(ctx) {
+		return
+	}
+	resp, err := h.handler.applyAction(ctx, h.game, c.userID, action)
+	if err != nil {
+		h.handler.log.Error(err)
+		h.replyError(c, env.Seq, err)
+		return
+	}
+	resp.Clock = clockState(h.game)
+	h.armFlagTimer()
+
+	event := h.event(eventType(action.Type), resp)
+	h.reply(c, protocol.TypeAck, protocol.Ack{ReplyTo: env.Seq})
+	if notifiesBoth(resp) {
+		h.send(c, event)
+	}
+	if opponent, ok := h.players[c.color.Opponent()]; ok {
+		h.send(opponent, event)
+	} else if h.game.BotColor() != c.color.Opponent() {
+		h.reply(c, protocol.TypeNotice, protocol.Notice{Code: protocol.NoticeOpponentOffline, Message: "Оппонент не подключён"})
+	}
+	h.notifySpectators(event)
+
+	if resp.Status == statuses.StatusCompleted {
+		h.finish()
+		return
+	}
+	h.botTurn()
+}
+
+// broadcast отправляет событие обоим игрокам и всем зрителям.
+func (h *hub) broadcast(event protocol.Envelope) {
+	for _, c := range h.players {
+		h.send(c, event)
+	}
+	h.notifySpectators(event)
+}
+
+// refreshPlayers подгружает из базы игроков, присоединившихся к партии после запуска хаба.
+func (h *hub) refreshPlayers() {
+	if h.game.PlayerBlack != "" && h.game.PlayerWhite != "" {
+		return
+	}
+	play, err := h.handler.gameUC.GetGameBySecreteKey(context.Background(), h.game.GameKeySecret)
+	if err != nil {
+		h.handler.log.Error("Ошибка загрузки игроков партии:", err)
+		return
+	}
+	h.game.PlayerBlack = play.PlayerBlack
+	h.game.PlayerWhite = play.PlayerWhite
+}
+
+// finish останавливает таймеры завершённой партии, снимает хаб с учёта и аренду партии.
+// Клиенты остаются подключены, пока сами не уйдут, а новые подключения получат партию из базы.
+func (h *hub) finish() {
+	h.stopTimers()
+	h.handler.hubs.remove(h.game.GameKeySecret, h)
+	h.releaseLease()
+}
+
+// stopTimers останавливает таймеры флага и присуждения партии.
+func (h *hub) stopTimers() {
+	if h.flagTimer != nil {
+		h.flagTimer.Stop()
+		h.flagTimer = nil
+	}
+	for color, timer := range h.abandonTimers {
+		timer.Stop()
+		delete(h.abandonTimers, color)
+	}
+}
+
+// armFlagTimer перезапускает таймер, который завершит партию, когда у игрока, чей ход,
+// истечёт время. Флаг падает, даже если этот игрок отключился.
+func (h *hub) armFlagTimer() {
+	if h.flagTimer != nil {
+		h.flagTimer.Stop()
+		h.flagTimer = nil
+	}
+	if !h.owner || h.game.Clock == nil || h.game.Clock.Running() == board.Empty {
+		return
+	}
+	left := h.game.Clock.Remaining(h.game.Clock.Running(), time.Now())
+	h.flagTimer = time.AfterFunc(left, func() {
+		h.submit(func() { h.flagFall(context.Background()) })
+	})
+}
+
+// flagFall завершает партию поражением по времени и сообщает результат всем участникам.
+// Возвращает true, если партия завершилась.
+func (h *hub) flagFall(ctx context.Context) bool {
+	finished, sgfString, err := h.handler.gameUC.CheckFlag(ctx, h.game, time.Now())
+	if err != nil {
+		h.handler.log.Error("Ошибка завершения партии по времени:", err)
+	}
+	if !finished {
+		return false
+	}
+
+	h.broadcast(h.event(protocol.TypeGameState, game.GameStateResponse{
+		SGF:    sgfString,
+		Status: h.game.Status,
+		Result: h.game.Result.SgfString(),
+		Clock:  clockState(h.game),
+	}))
+	h.finish()
+	return true
+}
+
+// resignLeaver засчитывает сдачу игроку, покинувшему начатую партию, и рассылает результат.
+// Возвращает false, если партию ведёт не этот хаб или пользователь не сидит за доской.
+func (h *hub) resignLeaver(userID string) (bool, error) {
+	if !h.owner || h.game.Status == statuses.StatusCompleted || h.game.PlayerBlack == "" || h.game.PlayerWhite == "" {
+		return false, nil
+	}
+	if userID != h.game.PlayerBlack && userID != h.game.PlayerWhite {
+		return false, nil
+	}
+	resp, err := h.handler.applyAction(context.Background(), h.game, userID, game.GameAction{Type: game.ActionResign})
+	if err != nil {
+		return true, err
+	}
+	resp.Clock = clockState(h.game)
+	h.broadcast(h.event(eventType(game.ActionResign), resp))
+	h.finish()
+	return true, nil
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"team_exe/internal/domain/matchmaking"
+	errs "team_exe/internal/errors"
+	"team_exe/internal/httpresponse"
+	"team_exe/internal/utils"
+	"time"
+)
+
+// matchmakingInterval период, с которым очередь подбора сводится в пары.
+const matchmakingInterval = 3 * time.Second
+
+// RunMatchmaking периодически сводит заявки очереди подбора в партии, пока не отменён ctx.
+func (g *GameHandler) RunMatchmaking(ctx context.Context) {
+	ticker := time.NewTicker(matchmakingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			created, err := g.matchmakingUC.TryMatch(ctx)
+			if err != nil {
+				g.log.Error("Ошибка подбора соперников:", err)
+			}
+			if created > 0 {
+				g.log.Infof("Подбор соперников: создано партий: %d", created)
+			}
+		}
+	}
+}
+
+// HandleJoinMatchmaking godoc
+// @Summary Встать в очередь подбора соперника
+// @Description Ставит текущего пользователя в очередь автоматического подбора по рейтингу, размеру доски и контролю времени. Допустимая разница рейтингов растёт со временем ожидания. Требуется авторизация через cookie.
+// @Tags matchmaking
+// @Accept json
+// @Produce json
+// @Param request body matchmaking.JoinRequest true "Размер доски, контроль времени и рейтинговость партии"
+// @Success 200 {object} matchmaking.Status "Состояние подбора"
+// @Failure 400 {object} httpresponse.ErrorResponse "Неверный запрос или пользователь уже участвует в игре"
+// @Failure 401 {object} httpresponse.ErrorResponse "Пользователь не авторизован"
+// @Failure 405 {object} httpresponse.ErrorResponse "Метод не разрешен"
+// @Router /joinMatchmaking [post]
+func (g *GameHandler) HandleJoinMatchmaking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		g.log.Error("Разрешен только метод POST")
+		httpresponse.WriteResponseWithStatus(w, http.StatusMethodNotAllowed, "Разрешен только метод POST")
+		return
+	}
+
+	userID := g.authHandler.GetUserID(w, r)
+	if userID == "" {
+		g.log.Error("UserID не найден в cookie")
+		httpresponse.WriteResponseWithStatus(w, http.StatusUnauthorized, "UserID не найден в cookie")
+		return
+	}
+
+	var req matchmaking.JoinRequest
+	if err := utils.DecodeJSONRequest(r, &req); err != nil {
+		g.log.Error("Ошибка декодирования JSON:", err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status, err := g.matchmakingUC.Join(r.Context(), userID, req)
+	if errors.Is(err, errs.ErrAlreadyInGame) {
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, "Пользователь уже участвует в игре")
+		return
+	}
+	if err != nil {
+		g.log.Error("Ошибка постановки в очередь подбора: ", err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	httpresponse.WriteResponseWithStatus(w, http.StatusOK, status)
+}
+
+// HandleLeaveMatchmaking godoc
+// @Summary Выйти из очереди подбора соперника
+// @Description Убирает текущего пользователя из очереди автоматического подбора. Требуется авторизация через cookie.
+// @Tags matchmaking
+// @Produce json
+// @Success 200 {object} JsonOKResponse "Пользователь вышел из очереди"
+// @Failure 401 {object} httpresponse.ErrorResponse "Пользователь не авторизован"
+// @Failure 405 {object} httpresponse.ErrorResponse "Метод не разрешен"
+// @Failure 500 {object} httpresponse.ErrorResponse "Ошибка сервера"
+// @Router /leaveMatchmaking [post]
+func (g *GameHandler) HandleLeaveMatchmaking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		g.log.Error("Разрешен только метод POST")
+		httpresponse.WriteResponseWithStatus(w, http.StatusMethodNotAllowed, "Разрешен только метод POST")
+		return
+	}
+
+	userID := g.authHandler.GetUserID(w, r)
+	if userID == "" {
+		g.log.Error("UserID не найден в cookie")
+		httpresponse.WriteResponseWithStatus(w, http.StatusUnauthorized, "UserID не найден в cookie")
+		return
+	}
+
+	if err := g.matchmakingUC.Leave(r.Context(), userID); err != nil {
+		g.log.Error("Ошибка выхода из очереди подбора: ", err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httpresponse.WriteResponseWithStatus(w, http.StatusOK, JsonOKResponse{Text: "Пользователь вышел из очереди подбора"})
+}
+
+// HandleMatchmakingStatus godoc
+// @Summary Состояние подбора соперника
+// @Description Сообщает, ищет ли текущий пользователь соперника, и возвращает ключ созданной партии и цвет, когда соперник найден. Требуется авторизация через cookie.
+// @Tags matchmaking
+// @Produce json
+// @Success 200 {object} matchmaking.Status "Состояние подбора"
+// @Failure 401 {object} httpresponse.ErrorResponse "Пользователь не авторизован"
+// @Failure 500 {object} httpresponse.ErrorResponse "Ошибка сервера"
+// @Router /matchmakingStatus [get]
+func (g *GameHandler) HandleMatchmakingStatus(w http.ResponseWriter, r *http.Request) {
+	userID := g.authHandler.GetUserID(w, r)
+	if userID == "" {
+		g.log.Error("UserID не найден в cookie")
+		httpresponse.WriteResponseWithStatus(w, http.StatusUnauthorized, "UserID не найден в cookie")
+		return
+	}
+
+	status, err := g.matchmakingUC.Status(r.Context(), userID)
+	if err != nil {
+		g.log.Error("Ошибка получения состояния подбора: ", err)
+		httpresponse.WriteResponseWithStatus(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httpresponse.WriteResponseWithStatus(w, http.StatusOK, status)
+}
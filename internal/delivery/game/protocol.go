@@ -0,0 +1,152 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/chat"
+	"team_exe/internal/domain/game"
+	"team_exe/internal/domain/protocol"
+	"team_exe/internal/statuses"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// event оформляет событие партии со следующим номером и запоминает его в журнале.
+func (h *hub) event(typ string, payload any) protocol.Envelope {
+	env, err := h.feed.Next(typ, payload)
+	if err != nil {
+		h.handler.log.Error("Ошибка кодирования события партии:", err)
+	}
+	return env
+}
+
+// resync досылает клиенту события после sinceSeq, а если их уже нет в журнале или номер
+// не задан, отправляет полное состояние партии. Игрок не получает сообщений канала зрителей.
+func (h *hub) resync(c *client, sinceSeq *int64) {
+	player := c.color != board.Empty
+	if sinceSeq != nil {
+		if events, ok := h.feed.Since(*sinceSeq); ok {
+			for _, env := range events {
+				if player && h.hiddenFromPlayers(env) {
+					continue
+				}
+				h.send(c, env)
+			}
+			return
+		}
+	}
+
+	snapshot := h.handler.gameUC.Snapshot(h.game, player, time.Now())
+	snapshot.Connected = map[string]bool{
+		board.Black.String(): h.connected(board.Black),
+		board.White.String(): h.connected(board.White),
+	}
+	snapshot.Spectators = len(h.spectators)
+	snapshot.Seq = h.feed.Seq()
+	h.reply(c, protocol.TypeGameState, snapshot)
+}
+
+// hiddenFromPlayers сообщает, что событие - сообщение канала зрителей, которое игрокам
+// не показывается, пока партия не завершена.
+func (h *hub) hiddenFromPlayers(env protocol.Envelope) bool {
+	if env.Type != protocol.TypeChat || h.game.Status == statuses.StatusCompleted {
+		return false
+	}
+	var resp game.GameStateResponse
+	if err := json.Unmarshal(env.Payload, &resp); err != nil || resp.Chat == nil {
+		return false
+	}
+	return resp.Chat.Channel == chat.ChannelKibitz
+}
+
+// handleResync отвечает на запрос resync клиента.
+func (h *hub) handleResync(c *client, env protocol.Envelope) {
+	var req protocol.Resync
+	if len(env.Payload) > 0 {
+		if err := json.Unmarshal(env.Payload, &req); err != nil {
+			h.replyError(c, env.Seq, protocol.ErrBadMessage)
+			return
+		}
+	}
+	h.resync(c, req.SinceSeq)
+}
+
+// sinceSeqParam разбирает номер последнего полученного события из параметра since_seq.
+func sinceSeqParam(r *http.Request) *int64 {
+	seq, err := strconv.ParseInt(r.URL.Query().Get("since_seq"), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &seq
+}
+
+// reply отправляет ответ одному клиенту вне нумерации событий партии.
+func (h *hub) reply(c *client, typ string, payload any) {
+	env, err := protocol.New(typ, 0, payload)
+	if err != nil {
+		h.handler.log.Error("Ошибка кодирования ответа:", err)
+		return
+	}
+	h.send(c, env)
+}
+
+// replyError сообщает клиенту об ошибке обработки его сообщения с номером replyTo.
+func (h *hub) replyError(c *client, replyTo int64, err error) {
+	h.reply(c, protocol.TypeError, errorPayload(replyTo, err))
+}
+
+// errorPayload описывает ошибку машиночитаемым кодом.
+func errorPayload(replyTo int64, err error) protocol.Error {
+	return protocol.Error{Code: protocol.ErrorCode(err), Message: err.Error(), ReplyTo: replyTo}
+}
+
+// rejectConn сообщает клиенту об ошибке до подключения к партии и закрывает соединение.
+func (g *GameHandler) rejectConn(conn *websocket.Conn, err error) {
+	if env, encodeErr := protocol.New(protocol.TypeError, 0, errorPayload(0, err)); encodeErr == nil {
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		conn.WriteJSON(env)
+	}
+	conn.Close()
+}
+
+// readEnvelope читает следующее сообщение клиента. Ошибка protocol.ErrBadMessage означает,
+// что соединение живо, но сообщение не разобрано.
+func readEnvelope(ws *websocket.Conn) (protocol.Envelope, error) {
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		return protocol.Envelope{}, err
+	}
+	return protocol.Decode(data)
+}
+
+// decodeAction переводит сообщение клиента в игровое действие.
+func decodeAction(env protocol.Envelope) (game.GameAction, error) {
+	switch env.Type {
+	case protocol.TypeMove, protocol.TypePass, protocol.TypeResign, protocol.TypeChat,
+		protocol.TypeToggleDead, protocol.TypeAcceptScore,
+		protocol.TypeUndoRequest, protocol.TypeUndoAccept, protocol.TypeUndoDecline:
+	default:
+		return game.GameAction{}, protocol.ErrUnknownType
+	}
+
+	var action game.GameAction
+	if len(env.Payload) > 0 {
+		if err := json.Unmarshal(env.Payload, &action); err != nil {
+			return game.GameAction{}, protocol.ErrBadMessage
+		}
+	}
+	action.Type = env.Type
+	return action, nil
+}
+
+// eventType возвращает тип события, которым рассылается результат действия.
+func eventType(actionType string) string {
+	switch actionType {
+	case game.ActionMove, game.ActionPass, game.ActionResign, game.ActionChat:
+		return actionType
+	}
+	return protocol.TypeGameState
+}
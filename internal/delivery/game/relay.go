@@ -0,0 +1,282 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/protocol"
+	"team_exe/internal/statuses"
+	"time"
+)
+
+// defaultGameLeaseTTL срок аренды партии, если GAME_LEASE_TTL не задан.
+const defaultGameLeaseTTL = 15 * time.Second
+
+// Виды сообщений между экземплярами сервера, держащими сокеты одной партии.
+const (
+	relayJoin    = "join"    // клиент подключился к экземпляру, не владеющему партией
+	relayLeave   = "leave"   // клиент отключился
+	relayCommand = "command" // сообщение клиента для владельца партии
+	relayDeliver = "deliver" // сообщение владельца партии клиенту
+	relayDrop    = "drop"    // владелец партии отключает клиента
+	relayOwner   = "owner"   // экземпляр стал владельцем партии
+)
+
+// relayMessage сообщение канала партии в Redis.
+type relayMessage struct {
+	Kind     string             `json:"kind"`
+	Sender   string             `json:"sender"`
+	Client   string             `json:"client,omitempty"`
+	UserID   string             `json:"user_id,omitempty"`
+	SinceSeq *int64             `json:"since_seq,omitempty"`
+	Envelope *protocol.Envelope `json:"envelope,omitempty"`
+}
+
+// leaseTTL срок, на который экземпляр арендует партию.
+func (g *GameHandler) leaseTTL() time.Duration {
+	if g.cfg.GameLeaseTTL > 0 {
+		return g.cfg.GameLeaseTTL
+	}
+	return defaultGameLeaseTTL
+}
+
+// startRelay подписывает хаб на канал партии и пытается арендовать партию. Если аренду
+// получить не удалось, хаб не проверяет ходы и пробует арендовать партию при продлении.
+func (h *hub) startRelay() {
+	ctx := context.Background()
+	unsubscribe, err := h.handler.relay.Subscribe(ctx, h.game.GameKeySecret, func(data []byte) {
+		h.submit(func() { h.receive(data) })
+	})
+	if err != nil {
+		h.handler.log.Error("Ошибка подписки на канал партии:", err)
+	} else {
+		h.unsubscribe = unsubscribe
+	}
+
+	acquired, err := h.handler.relay.AcquireLease(ctx, h.game.GameKeySecret, h.token, h.handler.leaseTTL())
+	if err != nil {
+		h.handler.log.Error("Ошибка аренды партии:", err)
+	}
+	h.owner = acquired
+	if h.owner {
+		h.leasedAt = time.Now()
+		h.publish(relayMessage{Kind: relayOwner})
+	}
+
+	go func() {
+		ticker := time.NewTicker(h.handler.leaseTTL() / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-h.done:
+				return
+			case <-ticker.C:
+				h.submit(h.renewLease)
+			}
+		}
+	}()
+}
+
+// stopRelay снимает аренду партии и отписывается от её канала.
+func (h *hub) stopRelay() {
+	if h.owner {
+		h.releaseLease()
+	}
+	if h.unsubscribe != nil {
+		h.unsubscribe()
+	}
+}
+
+// publish отправляет сообщение остальным экземплярам, держащим сокеты партии.
+func (h *hub) publish(msg relayMessage) {
+	msg.Sender = h.token
+	data, err := json.Marshal(msg)
+	if err != nil {
+		h.handler.log.Error("Ошибка кодирования сообщения канала партии:", err)
+		return
+	}
+	if err = h.handler.relay.Publish(context.Background(), h.game.GameKeySecret, data); err != nil {
+		h.handler.log.Error("Ошибка публикации в канал партии:", err)
+	}
+}
+
+// receive обрабатывает сообщение другого экземпляра. Сообщения для клиентов доставляются
+// своим клиентам, а подключения и ходы удалённых клиентов обрабатывает только владелец партии.
+func (h *hub) receive(data []byte) {
+	var msg relayMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		h.handler.log.Error("Повреждённое сообщение канала партии:", err)
+		return
+	}
+	if msg.Sender == h.token {
+		return
+	}
+
+	switch msg.Kind {
+	case relayDeliver:
+		c, ok := h.clients[msg.Client]
+		if !ok || msg.Envelope == nil {
+			return
+		}
+		h.lastSeq = max(h.lastSeq, msg.Envelope.Seq)
+		h.send(c, *msg.Envelope)
+	case relayDrop:
+		if c, ok := h.clients[msg.Client]; ok {
+			h.drop(c)
+		}
+	case relayOwner:
+		if h.owner {
+			h.stepDown()
+		}
+		h.rejoin()
+	case relayJoin:
+		if !h.owner {
+			return
+		}
+		if old, ok := h.proxies[msg.Client]; ok {
+			h.forget(old)
+		}
+		proxy := &client{id: msg.Client, userID: msg.UserID, remote: true}
+		h.proxies[msg.Client] = proxy
+		h.join(proxy, msg.SinceSeq)
+	case relayCommand:
+		if proxy, ok := h.proxies[msg.Client]; ok && h.owner && msg.Envelope != nil {
+			h.handle(proxy, *msg.Envelope)
+		}
+	case relayLeave:
+		if proxy, ok := h.proxies[msg.Client]; ok && h.owner {
+			delete(h.proxies, msg.Client)
+			proxy.closed = true
+			h.leave(proxy)
+		}
+	}
+}
+
+// connect подключает клиента этого экземпляра к партии: у владельца партии напрямую,
+// иначе через канал партии.
+func (h *hub) connect(c *client, sinceSeq *int64) {
+	h.clients[c.id] = c
+	if h.owner {
+		h.join(c, sinceSeq)
+		return
+	}
+	h.publish(relayMessage{Kind: relayJoin, Client: c.id, UserID: c.userID, SinceSeq: sinceSeq})
+}
+
+// disconnect отключает клиента этого экземпляра.
+func (h *hub) disconnect(c *client) {
+	if _, ok := h.clients[c.id]; !ok {
+		return
+	}
+	delete(h.clients, c.id)
+	if h.owner {
+		h.leave(c)
+		return
+	}
+	h.drop(c)
+	h.publish(relayMessage{Kind: relayLeave, Client: c.id})
+}
+
+// dispatch обрабатывает сообщение клиента этого экземпляра или пересылает его владельцу партии.
+func (h *hub) dispatch(c *client, env protocol.Envelope) {
+	if h.owner {
+		h.handle(c, env)
+		return
+	}
+	h.publish(relayMessage{Kind: relayCommand, Client: c.id, Envelope: &env})
+}
+
+// renewLease продлевает аренду партии. Экземпляр, потерявший аренду, уступает партию,
+// а получивший свободную аренду становится владельцем. Если аренду не удаётся продлить
+// дольше её срока, владелец тоже уступает партию: аренда истекла и могла достаться другому.
+func (h *hub) renewLease() {
+	if h.owner && h.game.Status == statuses.StatusCompleted {
+		return
+	}
+	acquired, err := h.handler.relay.AcquireLease(context.Background(), h.game.GameKeySecret, h.token, h.handler.leaseTTL())
+	if err != nil {
+		h.handler.log.Error("Ошибка продления аренды партии:", err)
+		if h.owner && time.Since(h.leasedAt) >= h.handler.leaseTTL() {
+			h.stepDown()
+			h.rejoin()
+		}
+		return
+	}
+	if acquired {
+		h.leasedAt = time.Now()
+	}
+	switch {
+	case acquired && !h.owner:
+		h.takeOver()
+	case !acquired && h.owner:
+		h.stepDown()
+		h.rejoin()
+	}
+}
+
+// releaseLease снимает аренду партии, чтобы её сразу мог подхватить другой экземпляр.
+func (h *hub) releaseLease() {
+	if err := h.handler.relay.ReleaseLease(context.Background(), h.game.GameKeySecret, h.token); err != nil {
+		h.handler.log.Error("Ошибка снятия аренды партии:", err)
+	}
+}
+
+// takeOver делает экземпляр владельцем партии: загружает её из базы, объявляет себя владельцем
+// и заново подключает своих клиентов. Клиенты других экземпляров подключатся по объявлению.
+func (h *hub) takeOver() {
+	play, err := h.handler.gameUC.GetGameBySecreteKey(context.Background(), h.game.GameKeySecret)
+	if err != nil {
+		h.handler.log.Error("Не удалось загрузить партию при смене владельца:", err)
+		h.releaseLease()
+		return
+	}
+	if err = h.handler.gameUC.RestoreLiveGame(&play); err != nil {
+		h.handler.log.Error("Ошибка восстановления партии:", err)
+	}
+	h.handler.log.Infof("Экземпляр стал владельцем партии %s", play.GameKeyPublic)
+
+	h.game = &play
+	h.owner = true
+	h.feed.Restart(max(h.lastSeq, h.feed.Seq()))
+	h.publish(relayMessage{Kind: relayOwner})
+	for _, c := range h.clients {
+		h.join(c, nil)
+	}
+	h.armFlagTimer()
+	for _, color := range []board.Color{board.Black, board.White} {
+		if _, ok := h.players[color]; !ok {
+			h.armAbandonTimer(color)
+		}
+	}
+	h.botTurn()
+}
+
+// stepDown снимает с экземпляра владение партией. Удалённые клиенты забываются без
+// уведомлений: они подключатся к новому владельцу.
+func (h *hub) stepDown() {
+	h.owner = false
+	h.stopTimers()
+	h.lastSeq = max(h.lastSeq, h.feed.Seq())
+	for _, proxy := range h.proxies {
+		proxy.closed = true
+	}
+	clear(h.proxies)
+	clear(h.players)
+	clear(h.spectators)
+}
+
+// rejoin заново подключает клиентов этого экземпляра к владельцу партии.
+func (h *hub) rejoin() {
+	for _, c := range h.clients {
+		h.publish(relayMessage{Kind: relayJoin, Client: c.id, UserID: c.userID})
+	}
+}
+
+// forget убирает клиента из партии без уведомлений.
+func (h *hub) forget(c *client) {
+	c.closed = true
+	if c.color != board.Empty && h.players[c.color] == c {
+		delete(h.players, c.color)
+	}
+	delete(h.spectators, c)
+}
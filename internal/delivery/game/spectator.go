@@ -0,0 +1,58 @@
+package game
+
+import (
+	"context"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/game"
+	"team_exe/internal/domain/protocol"
+	errs "team_exe/internal/errors"
+)
+
+// joinSpectator подключает зрителя к партии и отправляет ему состояние партии.
+// Ходить зритель не может, но может писать в канал зрителей.
+func (h *hub) joinSpectator(c *client, sinceSeq *int64) {
+	c.color = board.Empty
+	if err := h.handler.gameUC.CheckSpectator(h.game, len(h.spectators)); err != nil {
+		h.handler.log.Error("Зритель не подключён:", err)
+		h.replyError(c, 0, err)
+		h.drop(c)
+		return
+	}
+	h.spectators[c] = struct{}{}
+	h.resync(c, sinceSeq)
+	h.spectatorsChanged()
+	h.handler.log.Infof("Зритель %s подключился к партии %s", c.userID, h.game.GameKeyPublic)
+}
+
+// handleSpectator обрабатывает сообщение зрителя: ему доступен только чат.
+func (h *hub) handleSpectator(c *client, env protocol.Envelope) {
+	if env.Type != protocol.TypeChat {
+		h.replyError(c, env.Seq, errs.ErrNotAPlayer)
+		return
+	}
+	action, err := decodeAction(env)
+	if err != nil {
+		h.replyError(c, env.Seq, err)
+		return
+	}
+	message, err := h.handler.gameUC.PostChat(context.Background(), h.game, c.userID, action.Channel, action.Text)
+	if err != nil {
+		h.replyError(c, env.Seq, err)
+		return
+	}
+	h.reply(c, protocol.TypeAck, protocol.Ack{ReplyTo: env.Seq})
+	h.notifySpectators(h.event(protocol.TypeChat, game.GameStateResponse{Event: game.EventChat, Chat: &message}))
+}
+
+// notifySpectators рассылает событие всем зрителям партии.
+func (h *hub) notifySpectators(event protocol.Envelope) {
+	for c := range h.spectators {
+		h.send(c, event)
+	}
+}
+
+// spectatorsChanged сообщает игрокам и зрителям новое число зрителей.
+func (h *hub) spectatorsChanged() {
+	count := len(h.spectators)
+	h.broadcast(h.event(protocol.TypeGameState, game.GameStateResponse{Event: game.EventSpectators, Spectators: &count}))
+}
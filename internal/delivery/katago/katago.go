@@ -2,18 +2,27 @@ package katago
 
 import (
 	"encoding/json"
+	"errors"
 	"go.uber.org/zap"
 	"net/http"
 	"team_exe/internal/bootstrap"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/bot"
+	"team_exe/internal/domain/coord"
 	"team_exe/internal/domain/game"
+	"team_exe/internal/domain/rules"
+	errs "team_exe/internal/errors"
 	katagoUC "team_exe/internal/usecase/katago"
 	katagoProto "team_exe/microservices/proto"
 )
 
-type GenerateMoveRequest game.Moves
+type GenerateMoveRequest game.BotPosition
 
 type BotMoveResponse struct {
-	BotMove game.Move `json:"bot_move"`
+	BotMove     game.Move        `json:"bot_move"`
+	Resign      bool             `json:"resign,omitempty"` // бот сдался, ход не заполнен
+	Diagnostics game.Diagnostics `json:"diagnostics"`      // лучшие ходы, оценка счёта и вероятность победы
+	RequestID   string           `json:"request_id"`
 }
 type KatagoHandler struct {
 	cfg        bootstrap.Config
@@ -36,22 +45,45 @@ func (k *KatagoHandler) HandleGenerateMove(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	var movesToBot game.Moves
-	if err := json.NewDecoder(r.Body).Decode(&movesToBot); err != nil {
+	var position game.BotPosition
+	if err := json.NewDecoder(r.Body).Decode(&position); err != nil {
 		writeJSONError(k.log, w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
 		return
 	}
 
+	strength, err := bot.ParseLevel(r.URL.Query().Get("level"))
+	if err != nil {
+		writeJSONError(k.log, w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	ctx := r.Context()
 
-	botMove, err := katagoUC.GenMove(ctx, movesToBot, k.katagoGRPC)
+	botMove, botResponse, err := katagoUC.GenMove(ctx, position, strength, k.katagoGRPC)
+	if errors.Is(err, errs.ErrBotResigned) {
+		writeJSON(k.log, w, http.StatusOK, BotMoveResponse{
+			Resign:      true,
+			Diagnostics: botResponse.Diagnostics,
+			RequestID:   botResponse.RequestID,
+		})
+		return
+	}
+	if errors.Is(err, errs.ErrBadBoardSize) || errors.Is(err, rules.ErrUnknownRuleset) || errors.Is(err, board.ErrUnknownColor) ||
+		errors.Is(err, coord.ErrBadCoordinate) || errors.Is(err, coord.ErrOutOfRange) {
+		writeJSONError(k.log, w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		k.log.Errorf("failed to generate bot move: %v", err)
 		writeJSONError(k.log, w, http.StatusInternalServerError, "Failed to generate bot move")
 		return
 	}
 
-	resp := BotMoveResponse{BotMove: botMove}
+	resp := BotMoveResponse{
+		BotMove:     botMove,
+		Diagnostics: botResponse.Diagnostics,
+		RequestID:   botResponse.RequestID,
+	}
 
 	writeJSON(k.log, w, http.StatusOK, resp)
 }
@@ -0,0 +1,270 @@
+package board
+
+import (
+	"errors"
+	"strings"
+
+	"team_exe/internal/domain/coord"
+)
+
+var (
+	ErrOutOfBoard   = errors.New("point is outside of the board")
+	ErrOccupied     = errors.New("point is already occupied")
+	ErrSuicide      = errors.New("suicide is not allowed")
+	ErrKo           = errors.New("move repeats a previous position")
+	ErrWrongTurn    = errors.New("it is not this color's turn")
+	ErrUnknownColor = errors.New("unknown stone color")
+	ErrNoStone      = errors.New("there is no stone at the point")
+)
+
+// MaxSize наибольший поддерживаемый размер доски.
+const MaxSize = 25
+
+// Color цвет камня (или пустого пункта) на доске.
+type Color int8
+
+const (
+	Empty Color = iota
+	Black
+	White
+)
+
+// ParseColor разбирает цвет из обозначений "B"/"W" (регистр не важен, допускаются black/white).
+func ParseColor(s string) (Color, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "b", "black":
+		return Black, nil
+	case "w", "white":
+		return White, nil
+	}
+	return Empty, ErrUnknownColor
+}
+
+// String возвращает цвет в SGF-обозначении ("B" или "W").
+func (c Color) String() string {
+	switch c {
+	case Black:
+		return "B"
+	case White:
+		return "W"
+	}
+	return ""
+}
+
+// Opponent возвращает цвет соперника.
+func (c Color) Opponent() Color {
+	switch c {
+	case Black:
+		return White
+	case White:
+		return Black
+	}
+	return Empty
+}
+
+// Board позиция на доске вместе с очередностью хода, счётчиком пленных
+// и историей позиций для проверки правила ко.
+type Board struct {
+	size     int
+	grid     []Color
+	toPlay   Color
+	captures [3]int
+	koRule   KoRule
+	hash     uint64
+	history  []position
+	passes   int
+	dead     map[coord.Point]bool
+}
+
+// New создаёт пустую доску размера size x size, первыми ходят чёрные.
+func New(size int, koRule KoRule) *Board {
+	b := &Board{
+		size:   size,
+		grid:   make([]Color, size*size),
+		toPlay: Black,
+		koRule: koRule,
+	}
+	b.history = []position{{hash: b.hash, toPlay: b.toPlay}}
+	return b
+}
+
+// KoRule возвращает правило повторения позиций, по которому проверяются ходы.
+func (b *Board) KoRule() KoRule {
+	return b.koRule
+}
+
+// Hash возвращает хеш текущего расположения камней.
+func (b *Board) Hash() uint64 {
+	return b.hash
+}
+
+// Size возвращает размер доски.
+func (b *Board) Size() int {
+	return b.size
+}
+
+// ToPlay возвращает цвет, чей сейчас ход.
+func (b *Board) ToPlay() Color {
+	return b.toPlay
+}
+
+// At возвращает цвет камня в пункте p.
+func (b *Board) At(p coord.Point) Color {
+	return b.grid[b.index(p)]
+}
+
+// Captures возвращает число камней, взятых в плен игроком цвета c.
+func (b *Board) Captures(c Color) int {
+	return b.captures[c]
+}
+
+// Rows возвращает позицию построчно сверху вниз: "." - пустой пункт, "B" и "W" - камни.
+func (b *Board) Rows() []string {
+	rows := make([]string, b.size)
+	line := make([]byte, b.size)
+	for y := 0; y < b.size; y++ {
+		for x := 0; x < b.size; x++ {
+			switch b.grid[y*b.size+x] {
+			case Black:
+				line[x] = 'B'
+			case White:
+				line[x] = 'W'
+			default:
+				line[x] = '.'
+			}
+		}
+		rows[y] = string(line)
+	}
+	return rows
+}
+
+// OnBoard проверяет, что пункт лежит в пределах доски.
+func (b *Board) OnBoard(p coord.Point) bool {
+	return !p.Pass && p.OnBoard(b.size)
+}
+
+// Play делает ход цветом c в пункт p. Возвращает снятые с доски камни.
+// Ход не применяется, если он нарушает правила.
+func (b *Board) Play(c Color, p coord.Point) ([]coord.Point, error) {
+	if p.Pass {
+		return nil, b.Pass(c)
+	}
+	if c != Black && c != White {
+		return nil, ErrUnknownColor
+	}
+	if c != b.toPlay {
+		return nil, ErrWrongTurn
+	}
+	if !b.OnBoard(p) {
+		return nil, ErrOutOfBoard
+	}
+	if b.At(p) != Empty {
+		return nil, ErrOccupied
+	}
+
+	b.set(p, c)
+	var captured []coord.Point
+	for _, n := range b.neighbors(p) {
+		if b.At(n) != c.Opponent() {
+			continue
+		}
+		group, liberties := b.group(n)
+		if liberties == 0 {
+			for _, s := range group {
+				b.set(s, Empty)
+			}
+			captured = append(captured, group...)
+		}
+	}
+
+	if _, liberties := b.group(p); liberties == 0 {
+		b.set(p, Empty)
+		return nil, ErrSuicide
+	}
+
+	next := position{hash: b.hash, toPlay: c.Opponent()}
+	if b.repeats(next) {
+		b.set(p, Empty)
+		for _, s := range captured {
+			b.set(s, c.Opponent())
+		}
+		return nil, ErrKo
+	}
+
+	b.captures[c] += len(captured)
+	b.toPlay = c.Opponent()
+	b.history = append(b.history, next)
+	b.passes = 0
+	return captured, nil
+}
+
+// Pass пропускает ход цветом c.
+func (b *Board) Pass(c Color) error {
+	if c != Black && c != White {
+		return ErrUnknownColor
+	}
+	if c != b.toPlay {
+		return ErrWrongTurn
+	}
+	b.toPlay = c.Opponent()
+	b.history = append(b.history, position{hash: b.hash, toPlay: b.toPlay})
+	b.passes++
+	return nil
+}
+
+// ConsecutivePasses возвращает число пасов подряд, сделанных с последнего хода камнем.
+func (b *Board) ConsecutivePasses() int {
+	return b.passes
+}
+
+func (b *Board) index(p coord.Point) int {
+	return p.Y*b.size + p.X
+}
+
+func (b *Board) set(p coord.Point, c Color) {
+	i := b.index(p)
+	if old := b.grid[i]; old != Empty {
+		b.hash ^= zobristKey(i, old)
+	}
+	if c != Empty {
+		b.hash ^= zobristKey(i, c)
+	}
+	b.grid[i] = c
+}
+
+func (b *Board) neighbors(p coord.Point) []coord.Point {
+	result := make([]coord.Point, 0, 4)
+	for _, d := range [4]coord.Point{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}} {
+		n := coord.Point{X: p.X + d.X, Y: p.Y + d.Y}
+		if b.OnBoard(n) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// group возвращает камни группы, в которую входит p, и число её дамэ.
+func (b *Board) group(p coord.Point) ([]coord.Point, int) {
+	color := b.At(p)
+	visited := map[coord.Point]bool{p: true}
+	liberties := make(map[coord.Point]bool)
+	stack := []coord.Point{p}
+	var stones []coord.Point
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		stones = append(stones, cur)
+		for _, n := range b.neighbors(cur) {
+			switch b.At(n) {
+			case Empty:
+				liberties[n] = true
+			case color:
+				if !visited[n] {
+					visited[n] = true
+					stack = append(stack, n)
+				}
+			}
+		}
+	}
+	return stones, len(liberties)
+}
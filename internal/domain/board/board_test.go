@@ -0,0 +1,206 @@
+package board
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"team_exe/internal/domain/coord"
+)
+
+// setupBoard расставляет камни по строкам сверху вниз: "B" и "W" - камни, "." - пусто.
+func setupBoard(t *testing.T, koRule KoRule, toPlay Color, rows ...string) *Board {
+	t.Helper()
+	b := New(len(rows), koRule)
+	for y, row := range rows {
+		for x, c := range row {
+			var color Color
+			switch c {
+			case 'B':
+				color = Black
+			case 'W':
+				color = White
+			default:
+				continue
+			}
+			if err := b.Setup(color, coord.Point{X: x, Y: y}); err != nil {
+				t.Fatalf("setup %c at %d,%d: %v", c, x, y, err)
+			}
+		}
+	}
+	b.SetToPlay(toPlay)
+	return b
+}
+
+// point разбирает пункт в нотации SGF или "pass".
+func point(t *testing.T, b *Board, s string) coord.Point {
+	t.Helper()
+	p, err := coord.Parse(s, b.Size())
+	if err != nil {
+		t.Fatalf("parse %q: %v", s, err)
+	}
+	return p
+}
+
+func TestPlayCaptures(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []string
+		toPlay   Color
+		move     string
+		captured int
+		after    []string
+	}{
+		{
+			name:     "stone in the corner",
+			rows:     []string{"BW...", ".....", ".....", ".....", "....."},
+			toPlay:   White,
+			move:     "ab",
+			captured: 1,
+			after:    []string{".W...", "W....", ".....", ".....", "....."},
+		},
+		{
+			name:     "group of two",
+			rows:     []string{"BBW..", "W....", ".....", ".....", "....."},
+			toPlay:   White,
+			move:     "bb",
+			captured: 2,
+			after:    []string{"..W..", "WW...", ".....", ".....", "....."},
+		},
+		{
+			name:     "capture makes a move without liberties legal",
+			rows:     []string{".WB..", "WB...", "B....", ".....", "....."},
+			toPlay:   Black,
+			move:     "aa",
+			captured: 2,
+			after:    []string{"B.B..", ".B...", "B....", ".....", "....."},
+		},
+		{
+			name:     "no capture while a liberty remains",
+			rows:     []string{"BW...", ".....", ".....", ".....", "....."},
+			toPlay:   White,
+			move:     "cc",
+			captured: 0,
+			after:    []string{"BW...", ".....", "..W..", ".....", "....."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := setupBoard(t, KoSimple, tt.toPlay, tt.rows...)
+			captured, err := b.Play(tt.toPlay, point(t, b, tt.move))
+			if err != nil {
+				t.Fatalf("Play: %v", err)
+			}
+			if len(captured) != tt.captured {
+				t.Errorf("captured %d stones, want %d", len(captured), tt.captured)
+			}
+			if got := b.Captures(tt.toPlay); got != tt.captured {
+				t.Errorf("Captures = %d, want %d", got, tt.captured)
+			}
+			if got := strings.Join(b.Rows(), "/"); got != strings.Join(tt.after, "/") {
+				t.Errorf("board = %s, want %s", got, strings.Join(tt.after, "/"))
+			}
+			if b.ToPlay() != tt.toPlay.Opponent() {
+				t.Errorf("ToPlay = %v, want %v", b.ToPlay(), tt.toPlay.Opponent())
+			}
+		})
+	}
+}
+
+func TestPlayRejectsIllegalMoves(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []string
+		color   Color
+		move    string
+		wantErr error
+	}{
+		{
+			name:    "single stone suicide",
+			rows:    []string{".W...", "W....", ".....", ".....", "....."},
+			color:   Black,
+			move:    "aa",
+			wantErr: ErrSuicide,
+		},
+		{
+			name:    "group suicide",
+			rows:    []string{"B.W..", "WW...", ".....", ".....", "....."},
+			color:   Black,
+			move:    "ba",
+			wantErr: ErrSuicide,
+		},
+		{
+			name:    "occupied point",
+			rows:    []string{"B....", ".....", ".....", ".....", "....."},
+			color:   Black,
+			move:    "aa",
+			wantErr: ErrOccupied,
+		},
+		{
+			name:    "out of turn",
+			rows:    []string{".....", ".....", ".....", ".....", "....."},
+			color:   White,
+			move:    "cc",
+			wantErr: ErrWrongTurn,
+		},
+		{
+			name:    "out of turn pass",
+			rows:    []string{".....", ".....", ".....", ".....", "....."},
+			color:   White,
+			move:    "pass",
+			wantErr: ErrWrongTurn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := setupBoard(t, KoSimple, Black, tt.rows...)
+			before := strings.Join(b.Rows(), "/")
+			hash := b.Hash()
+
+			_, err := b.Play(tt.color, point(t, b, tt.move))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Play error = %v, want %v", err, tt.wantErr)
+			}
+			if got := strings.Join(b.Rows(), "/"); got != before {
+				t.Errorf("board changed to %s, want %s", got, before)
+			}
+			if b.Hash() != hash {
+				t.Error("hash changed after rejected move")
+			}
+			if b.ToPlay() != Black {
+				t.Errorf("ToPlay = %v, want %v", b.ToPlay(), Black)
+			}
+		})
+	}
+}
+
+func TestPlayOutOfBoard(t *testing.T) {
+	b := New(9, KoSimple)
+	if _, err := b.Play(Black, coord.Point{X: 9, Y: 0}); !errors.Is(err, ErrOutOfBoard) {
+		t.Fatalf("Play error = %v, want %v", err, ErrOutOfBoard)
+	}
+}
+
+func TestConsecutivePasses(t *testing.T) {
+	b := New(9, KoSimple)
+	steps := []struct {
+		color Color
+		move  string
+		want  int
+	}{
+		{Black, "pass", 1},
+		{White, "pass", 2},
+		{Black, "cc", 0},
+		{White, "pass", 1},
+	}
+	for _, step := range steps {
+		if _, err := b.Play(step.color, point(t, b, step.move)); err != nil {
+			t.Fatalf("%v %s: %v", step.color, step.move, err)
+		}
+		if got := b.ConsecutivePasses(); got != step.want {
+			t.Errorf("after %v %s ConsecutivePasses = %d, want %d", step.color, step.move, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package board
+
+import (
+	"errors"
+
+	"team_exe/internal/domain/coord"
+)
+
+var ErrBadHandicap = errors.New("handicap is not supported for this board size")
+
+// MaxHandicap наибольшее число камней форы.
+const MaxHandicap = 9
+
+// FixedHandicap возвращает пункты фиксированной расстановки n камней форы на хоси.
+// Для чётных размеров доски центральные пункты отсутствуют, поэтому фора ограничена четырьмя камнями.
+func FixedHandicap(size int, n int) ([]coord.Point, error) {
+	if n < 2 || n > MaxHandicap || size < 7 {
+		return nil, ErrBadHandicap
+	}
+	if size%2 == 0 && n > 4 {
+		return nil, ErrBadHandicap
+	}
+
+	edge := 3
+	if size < 13 {
+		edge = 2
+	}
+	low, mid, high := edge, size/2, size-1-edge
+
+	// порядок расстановки: по диагонали, затем центр и середины сторон
+	corners := []coord.Point{{X: high, Y: low}, {X: low, Y: high}, {X: high, Y: high}, {X: low, Y: low}}
+	sides := []coord.Point{{X: low, Y: mid}, {X: high, Y: mid}, {X: mid, Y: low}, {X: mid, Y: high}}
+	center := coord.Point{X: mid, Y: mid}
+
+	switch {
+	case n <= 4:
+		return corners[:n], nil
+	case n == 5:
+		return append(corners[:4:4], center), nil
+	case n == 6:
+		return append(corners[:4:4], sides[:2]...), nil
+	case n == 7:
+		return append(append(corners[:4:4], sides[:2]...), center), nil
+	case n == 8:
+		return append(corners[:4:4], sides...), nil
+	}
+	return append(append(corners[:4:4], sides...), center), nil
+}
+
+// Setup ставит камень цвета c без хода, как свойства AB/AW в SGF.
+// Получившаяся позиция становится начальной для проверки правила ко.
+func (b *Board) Setup(c Color, p coord.Point) error {
+	if c != Black && c != White {
+		return ErrUnknownColor
+	}
+	if !b.OnBoard(p) {
+		return ErrOutOfBoard
+	}
+	if b.At(p) != Empty {
+		return ErrOccupied
+	}
+	b.set(p, c)
+	b.history = []position{{hash: b.hash, toPlay: b.toPlay}}
+	return nil
+}
+
+// SetToPlay передаёт очередь хода цвету c, например белым после расстановки форы.
+func (b *Board) SetToPlay(c Color) {
+	b.toPlay = c
+	b.history[len(b.history)-1].toPlay = c
+}
@@ -0,0 +1,43 @@
+package board
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFixedHandicap(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		n       int
+		want    []string
+		wantErr error
+	}{
+		{name: "two stones on 19x19", size: 19, n: 2, want: []string{"pd", "dp"}},
+		{name: "four stones on 19x19", size: 19, n: 4, want: []string{"pd", "dp", "pp", "dd"}},
+		{name: "five stones on 9x9 take the center", size: 9, n: 5, want: []string{"gc", "cg", "gg", "cc", "ee"}},
+		{name: "six stones on 13x13 take the sides", size: 13, n: 6, want: []string{"jd", "dj", "jj", "dd", "dg", "jg"}},
+		{name: "nine stones on 19x19", size: 19, n: 9, want: []string{"pd", "dp", "pp", "dd", "dj", "pj", "jd", "jp", "jj"}},
+		{name: "even board has no center", size: 10, n: 5, wantErr: ErrBadHandicap},
+		{name: "one stone is not a handicap", size: 19, n: 1, wantErr: ErrBadHandicap},
+		{name: "too many stones", size: 19, n: 10, wantErr: ErrBadHandicap},
+		{name: "board too small", size: 5, n: 2, wantErr: ErrBadHandicap},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := FixedHandicap(tt.size, tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FixedHandicap error = %v, want %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, p := range points {
+				got = append(got, p.SGF())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FixedHandicap = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
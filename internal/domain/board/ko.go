@@ -0,0 +1,62 @@
+package board
+
+import "math/rand"
+
+// KoRule правило, запрещающее повторение позиций.
+type KoRule int8
+
+const (
+	// KoSimple запрещает немедленно отбивать ко: нельзя вернуть позицию, бывшую до хода соперника.
+	KoSimple KoRule = iota
+	// KoPositionalSuperko запрещает повторять любое прежнее расположение камней.
+	KoPositionalSuperko
+	// KoSituationalSuperko запрещает повторять прежнее расположение камней при той же очереди хода.
+	KoSituationalSuperko
+)
+
+var zobristTable = newZobristTable()
+
+// position запись истории позиций: хеш расположения камней и цвет, чей ход следующий.
+type position struct {
+	hash   uint64
+	toPlay Color
+}
+
+func newZobristTable() [][2]uint64 {
+	// фиксированное зерно даёт одинаковые хеши во всех экземплярах сервера
+	rnd := rand.New(rand.NewSource(19))
+	table := make([][2]uint64, MaxSize*MaxSize)
+	for i := range table {
+		table[i] = [2]uint64{rnd.Uint64(), rnd.Uint64()}
+	}
+	return table
+}
+
+func zobristKey(index int, c Color) uint64 {
+	return zobristTable[index][c-1]
+}
+
+// repeats проверяет, нарушает ли позиция next правило ко доски.
+func (b *Board) repeats(next position) bool {
+	switch b.koRule {
+	case KoSimple:
+		// позиция до последнего хода соперника
+		if len(b.history) < 2 {
+			return false
+		}
+		return b.history[len(b.history)-2].hash == next.hash
+	case KoPositionalSuperko:
+		for _, prev := range b.history {
+			if prev.hash == next.hash {
+				return true
+			}
+		}
+	case KoSituationalSuperko:
+		for _, prev := range b.history {
+			if prev.hash == next.hash && prev.toPlay == next.toPlay {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package board
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// koRows позиция с ко: чёрные берут камень на bb ходом в cb, белые могут отбить ходом в bb.
+var koRows = []string{
+	".BW..",
+	"BW.W.",
+	".BW..",
+	".....",
+	".....",
+}
+
+// playSequence делает ходы вида "B cb" или "W pass" и возвращает ошибку последнего из них.
+// Все ходы до последнего должны быть допустимы.
+func playSequence(t *testing.T, b *Board, moves []string) error {
+	t.Helper()
+	var err error
+	for i, m := range moves {
+		colorName, move, _ := strings.Cut(m, " ")
+		color, parseErr := ParseColor(colorName)
+		if parseErr != nil {
+			t.Fatalf("move %q: %v", m, parseErr)
+		}
+		_, err = b.Play(color, point(t, b, move))
+		if err != nil && i < len(moves)-1 {
+			t.Fatalf("move %q: %v", m, err)
+		}
+	}
+	return err
+}
+
+func TestKoRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		koRule  KoRule
+		moves   []string
+		wantErr error
+	}{
+		{
+			name:    "simple ko forbids immediate retake",
+			koRule:  KoSimple,
+			moves:   []string{"B cb", "W bb"},
+			wantErr: ErrKo,
+		},
+		{
+			name:    "simple ko allows retake after ko threats",
+			koRule:  KoSimple,
+			moves:   []string{"B cb", "W ee", "B ed", "W bb"},
+			wantErr: nil,
+		},
+		{
+			name:    "simple ko allows retake after two passes",
+			koRule:  KoSimple,
+			moves:   []string{"B cb", "W pass", "B pass", "W bb"},
+			wantErr: nil,
+		},
+		{
+			name:    "positional superko forbids immediate retake",
+			koRule:  KoPositionalSuperko,
+			moves:   []string{"B cb", "W bb"},
+			wantErr: ErrKo,
+		},
+		{
+			name:    "positional superko forbids retake after two passes",
+			koRule:  KoPositionalSuperko,
+			moves:   []string{"B cb", "W pass", "B pass", "W bb"},
+			wantErr: ErrKo,
+		},
+		{
+			name:    "positional superko allows retake in a new position",
+			koRule:  KoPositionalSuperko,
+			moves:   []string{"B cb", "W ee", "B ed", "W bb"},
+			wantErr: nil,
+		},
+		{
+			name:    "situational superko forbids retake after two passes",
+			koRule:  KoSituationalSuperko,
+			moves:   []string{"B cb", "W pass", "B pass", "W bb"},
+			wantErr: ErrKo,
+		},
+		{
+			name:    "situational superko allows retake in a new position",
+			koRule:  KoSituationalSuperko,
+			moves:   []string{"B cb", "W ee", "B ed", "W bb"},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := setupBoard(t, tt.koRule, Black, koRows...)
+			err := playSequence(t, b, tt.moves)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("last move error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKoRejectedMoveKeepsPosition(t *testing.T) {
+	b := setupBoard(t, KoSimple, Black, koRows...)
+	if err := playSequence(t, b, []string{"B cb"}); err != nil {
+		t.Fatalf("B cb: %v", err)
+	}
+	before := strings.Join(b.Rows(), "/")
+	hash := b.Hash()
+	captures := b.Captures(White)
+
+	if err := playSequence(t, b, []string{"W bb"}); !errors.Is(err, ErrKo) {
+		t.Fatalf("W bb error = %v, want %v", err, ErrKo)
+	}
+	if got := strings.Join(b.Rows(), "/"); got != before {
+		t.Errorf("board changed to %s, want %s", got, before)
+	}
+	if b.Hash() != hash {
+		t.Error("hash changed after rejected ko retake")
+	}
+	if b.Captures(White) != captures {
+		t.Errorf("white captures = %d, want %d", b.Captures(White), captures)
+	}
+	if b.ToPlay() != White {
+		t.Errorf("ToPlay = %v, want %v", b.ToPlay(), White)
+	}
+}
@@ -0,0 +1,166 @@
+package board
+
+import "team_exe/internal/domain/coord"
+
+// Scoring способ подсчёта очков.
+type Scoring int8
+
+const (
+	// ScoringArea подсчёт по площади: камни на доске плюс окружённые пустые пункты.
+	ScoringArea Scoring = iota
+	// ScoringTerritory подсчёт по территории: окружённые пустые пункты плюс пленные.
+	ScoringTerritory
+)
+
+// Score итог подсчёта очков. Коми уже учтено в очках белых.
+type Score struct {
+	Black          float64 `json:"black"`
+	White          float64 `json:"white"`
+	BlackTerritory int     `json:"black_territory"`
+	WhiteTerritory int     `json:"white_territory"`
+	BlackStones    int     `json:"black_stones"`
+	WhiteStones    int     `json:"white_stones"`
+	BlackCaptures  int     `json:"black_captures"` // пленные, взятые чёрными, включая мёртвые камни белых
+	WhiteCaptures  int     `json:"white_captures"`
+}
+
+// ToggleDead помечает группу, содержащую пункт p, мёртвой или снимает эту пометку.
+func (b *Board) ToggleDead(p coord.Point) error {
+	if !b.OnBoard(p) {
+		return ErrOutOfBoard
+	}
+	if b.At(p) == Empty {
+		return ErrNoStone
+	}
+	if b.dead == nil {
+		b.dead = make(map[coord.Point]bool)
+	}
+	group, _ := b.group(p)
+	mark := !b.dead[p]
+	for _, s := range group {
+		if mark {
+			b.dead[s] = true
+		} else {
+			delete(b.dead, s)
+		}
+	}
+	return nil
+}
+
+// MarkDead помечает мёртвым один камень, не трогая остальные камни группы.
+// Нужен, чтобы восстановить сохранённые пометки.
+func (b *Board) MarkDead(p coord.Point) error {
+	if !b.OnBoard(p) {
+		return ErrOutOfBoard
+	}
+	if b.At(p) == Empty {
+		return ErrNoStone
+	}
+	if b.dead == nil {
+		b.dead = make(map[coord.Point]bool)
+	}
+	b.dead[p] = true
+	return nil
+}
+
+// DeadStones возвращает камни, помеченные мёртвыми.
+func (b *Board) DeadStones() []coord.Point {
+	result := make([]coord.Point, 0, len(b.dead))
+	for y := 0; y < b.size; y++ {
+		for x := 0; x < b.size; x++ {
+			if p := (coord.Point{X: x, Y: y}); b.dead[p] {
+				result = append(result, p)
+			}
+		}
+	}
+	return result
+}
+
+// ClearDead снимает все пометки мёртвых камней.
+func (b *Board) ClearDead() {
+	b.dead = nil
+}
+
+// Score подсчитывает очки текущей позиции с учётом мёртвых камней и коми.
+func (b *Board) Score(method Scoring, komi float64) Score {
+	grid := make([]Color, len(b.grid))
+	copy(grid, b.grid)
+
+	score := Score{
+		BlackCaptures: b.captures[Black],
+		WhiteCaptures: b.captures[White],
+	}
+	for p := range b.dead {
+		switch b.At(p) {
+		case Black:
+			score.WhiteCaptures++
+		case White:
+			score.BlackCaptures++
+		}
+		grid[b.index(p)] = Empty
+	}
+
+	visited := make([]bool, len(grid))
+	for i, c := range grid {
+		switch c {
+		case Black:
+			score.BlackStones++
+			continue
+		case White:
+			score.WhiteStones++
+			continue
+		}
+		if visited[i] {
+			continue
+		}
+		size, owner := b.region(grid, visited, i)
+		switch owner {
+		case Black:
+			score.BlackTerritory += size
+		case White:
+			score.WhiteTerritory += size
+		}
+	}
+
+	switch method {
+	case ScoringArea:
+		score.Black = float64(score.BlackTerritory + score.BlackStones)
+		score.White = float64(score.WhiteTerritory+score.WhiteStones) + komi
+	case ScoringTerritory:
+		score.Black = float64(score.BlackTerritory + score.BlackCaptures)
+		score.White = float64(score.WhiteTerritory+score.WhiteCaptures) + komi
+	}
+	return score
+}
+
+// region обходит область пустых пунктов, начинающуюся с индекса start,
+// и возвращает её размер и цвет, который её окружает (Empty, если граничат оба цвета).
+func (b *Board) region(grid []Color, visited []bool, start int) (int, Color) {
+	borders := [3]bool{}
+	stack := []int{start}
+	visited[start] = true
+	size := 0
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		size++
+		for _, n := range b.neighbors(coord.Point{X: cur % b.size, Y: cur / b.size}) {
+			ni := b.index(n)
+			if grid[ni] != Empty {
+				borders[grid[ni]] = true
+				continue
+			}
+			if !visited[ni] {
+				visited[ni] = true
+				stack = append(stack, ni)
+			}
+		}
+	}
+	switch {
+	case borders[Black] && !borders[White]:
+		return size, Black
+	case borders[White] && !borders[Black]:
+		return size, White
+	}
+	return size, Empty
+}
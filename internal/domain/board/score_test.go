@@ -0,0 +1,158 @@
+package board
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"team_exe/internal/domain/coord"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []string
+		dead   []string // пункты групп, помеченных мёртвыми
+		method Scoring
+		komi   float64
+		want   Score
+	}{
+		{
+			name:   "area",
+			rows:   []string{".BW..", ".BW..", ".BW..", ".BW..", ".BW.."},
+			method: ScoringArea,
+			komi:   6.5,
+			want: Score{
+				Black: 10, White: 21.5,
+				BlackTerritory: 5, WhiteTerritory: 10,
+				BlackStones: 5, WhiteStones: 5,
+			},
+		},
+		{
+			name:   "territory",
+			rows:   []string{".BW..", ".BW..", ".BW..", ".BW..", ".BW.."},
+			method: ScoringTerritory,
+			komi:   6.5,
+			want: Score{
+				Black: 5, White: 16.5,
+				BlackTerritory: 5, WhiteTerritory: 10,
+				BlackStones: 5, WhiteStones: 5,
+			},
+		},
+		{
+			name:   "dame is nobody's territory",
+			rows:   []string{".B.W.", ".B.W.", ".B.W.", ".B.W.", ".B.W."},
+			method: ScoringArea,
+			want: Score{
+				Black: 10, White: 10,
+				BlackTerritory: 5, WhiteTerritory: 5,
+				BlackStones: 5, WhiteStones: 5,
+			},
+		},
+		{
+			name:   "dead stone counts as a prisoner under territory scoring",
+			rows:   []string{".BW..", ".BW..", "WBW..", ".BW..", ".BW.."},
+			dead:   []string{"ac"},
+			method: ScoringTerritory,
+			want: Score{
+				Black: 6, White: 10,
+				BlackTerritory: 5, WhiteTerritory: 10,
+				BlackStones: 5, WhiteStones: 5,
+				BlackCaptures: 1,
+			},
+		},
+		{
+			name:   "dead stone is removed under area scoring",
+			rows:   []string{".BW..", ".BW..", "WBW..", ".BW..", ".BW.."},
+			dead:   []string{"ac"},
+			method: ScoringArea,
+			want: Score{
+				Black: 10, White: 15,
+				BlackTerritory: 5, WhiteTerritory: 10,
+				BlackStones: 5, WhiteStones: 5,
+				BlackCaptures: 1,
+			},
+		},
+		{
+			name:   "dead group inside territory",
+			rows:   []string{".BW..", ".BW.B", ".BWBB", ".BW..", ".BW.."},
+			dead:   []string{"eb"},
+			method: ScoringTerritory,
+			want: Score{
+				Black: 5, White: 13,
+				BlackTerritory: 5, WhiteTerritory: 10,
+				BlackStones: 5, WhiteStones: 5,
+				WhiteCaptures: 3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := setupBoard(t, KoSimple, Black, tt.rows...)
+			for _, s := range tt.dead {
+				if err := b.ToggleDead(point(t, b, s)); err != nil {
+					t.Fatalf("ToggleDead(%s): %v", s, err)
+				}
+			}
+			if got := b.Score(tt.method, tt.komi); got != tt.want {
+				t.Errorf("Score = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleDead(t *testing.T) {
+	b := setupBoard(t, KoSimple, Black, "BB...", ".....", "...W.", "...W.", ".....")
+
+	if err := b.ToggleDead(point(t, b, "cc")); !errors.Is(err, ErrNoStone) {
+		t.Fatalf("ToggleDead on empty point error = %v, want %v", err, ErrNoStone)
+	}
+	if err := b.ToggleDead(coord.PassPoint); !errors.Is(err, ErrOutOfBoard) {
+		t.Fatalf("ToggleDead on pass error = %v, want %v", err, ErrOutOfBoard)
+	}
+
+	// пометка ставится на всю группу и выдаётся построчно
+	for _, s := range []string{"dd", "aa"} {
+		if err := b.ToggleDead(point(t, b, s)); err != nil {
+			t.Fatalf("ToggleDead(%s): %v", s, err)
+		}
+	}
+	want := []coord.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 3, Y: 2}, {X: 3, Y: 3}}
+	if got := b.DeadStones(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("DeadStones = %v, want %v", got, want)
+	}
+
+	// повторная пометка любого камня группы снимает её
+	if err := b.ToggleDead(point(t, b, "ba")); err != nil {
+		t.Fatalf("ToggleDead(ba): %v", err)
+	}
+	want = []coord.Point{{X: 3, Y: 2}, {X: 3, Y: 3}}
+	if got := b.DeadStones(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("DeadStones = %v, want %v", got, want)
+	}
+
+	b.ClearDead()
+	if got := b.DeadStones(); len(got) != 0 {
+		t.Errorf("DeadStones after ClearDead = %v, want none", got)
+	}
+}
+
+func TestMarkDead(t *testing.T) {
+	b := setupBoard(t, KoSimple, Black, "BB...", ".....", "...W.", "...W.", ".....")
+
+	if err := b.MarkDead(point(t, b, "cc")); !errors.Is(err, ErrNoStone) {
+		t.Fatalf("MarkDead on empty point error = %v, want %v", err, ErrNoStone)
+	}
+
+	// восстановленные пометки совпадают с сохранёнными, повторная пометка их не снимает
+	for _, s := range []string{"dc", "dd", "dc"} {
+		if err := b.MarkDead(point(t, b, s)); err != nil {
+			t.Fatalf("MarkDead(%s): %v", s, err)
+		}
+	}
+	want := []coord.Point{{X: 3, Y: 2}, {X: 3, Y: 3}}
+	if got := b.DeadStones(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("DeadStones = %v, want %v", got, want)
+	}
+}
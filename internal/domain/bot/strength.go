@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"errors"
+	"math"
+	"team_exe/internal/domain/rank"
+)
+
+var ErrUnknownLevel = errors.New("unknown bot level")
+
+// Пределы уровней бота: от 20k до 9d.
+const (
+	WeakestKyu   = 20
+	DefaultLevel = "5k"
+)
+
+// Strength профиль силы игры KataGo.
+type Strength struct {
+	MaxVisits   int     `json:"max_visits"`
+	MaxPlayouts int     `json:"max_playouts"`
+	MaxTime     float64 `json:"max_time"`    // секунд на ход
+	HumanRank   string  `json:"human_rank"`  // ранг, под который подстраивается человекоподобная модель
+	Temperature float64 `json:"temperature"` // случайность выбора хода, 0 - всегда лучший ход
+}
+
+// Levels возвращает названия уровней бота от слабого к сильному.
+func Levels() []string {
+	levels := make([]string, 0, WeakestKyu+rank.MaxDan)
+	for k := WeakestKyu; k >= 1; k-- {
+		levels = append(levels, rank.Rank{Kind: rank.Kyu, Level: k}.String())
+	}
+	for d := 1; d <= rank.MaxDan; d++ {
+		levels = append(levels, rank.Rank{Kind: rank.Dan, Level: d}.String())
+	}
+	return levels
+}
+
+// ParseLevel возвращает профиль силы уровня вида "15k" или "3d". Пустое название означает DefaultLevel.
+func ParseLevel(name string) (Strength, error) {
+	if name == "" {
+		name = DefaultLevel
+	}
+	r, err := rank.Parse(name)
+	if err != nil || r.Kind == rank.Pro || (r.Kind == rank.Kyu && r.Level > WeakestKyu) {
+		return Strength{}, ErrUnknownLevel
+	}
+	return strengthOf(r), nil
+}
+
+// strengthOf подбирает профиль уровня: чем сильнее уровень, тем глубже поиск
+// и тем меньше случайности в выборе хода.
+func strengthOf(r rank.Rank) Strength {
+	step := int(r.Value()) + WeakestKyu - 1 // 0 для 20k, 28 для 9d
+	visits := 1 << (step / 4)
+	return Strength{
+		MaxVisits:   visits,
+		MaxPlayouts: visits,
+		MaxTime:     round2(0.5 + 0.05*float64(step)),
+		HumanRank:   r.String(),
+		Temperature: round2(1 - 0.03*float64(step)),
+	}
+}
+
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
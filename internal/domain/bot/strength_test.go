@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		want    Strength
+		wantErr error
+	}{
+		{
+			name:  "weakest",
+			level: "20k",
+			want:  Strength{MaxVisits: 1, MaxPlayouts: 1, MaxTime: 0.5, HumanRank: "20k", Temperature: 1},
+		},
+		{
+			name:  "default",
+			level: "",
+			want:  Strength{MaxVisits: 8, MaxPlayouts: 8, MaxTime: 1.25, HumanRank: "5k", Temperature: 0.55},
+		},
+		{
+			name:  "long form",
+			level: "5 kyu",
+			want:  Strength{MaxVisits: 8, MaxPlayouts: 8, MaxTime: 1.25, HumanRank: "5k", Temperature: 0.55},
+		},
+		{
+			name:  "strongest",
+			level: "9d",
+			want:  Strength{MaxVisits: 128, MaxPlayouts: 128, MaxTime: 1.9, HumanRank: "9d", Temperature: 0.16},
+		},
+		{name: "weaker than the weakest level", level: "21k", wantErr: ErrUnknownLevel},
+		{name: "professional", level: "1p", wantErr: ErrUnknownLevel},
+		{name: "not a rank", level: "strong", wantErr: ErrUnknownLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLevel(tt.level)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseLevel(%q) error = %v, want %v", tt.level, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel(%q) = %+v, want %+v", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelsGetStronger(t *testing.T) {
+	levels := Levels()
+	if len(levels) != 29 || levels[0] != "20k" || levels[len(levels)-1] != "9d" {
+		t.Fatalf("Levels = %v, want 20k..9d", levels)
+	}
+
+	var prev Strength
+	for i, level := range levels {
+		s, err := ParseLevel(level)
+		if err != nil {
+			t.Fatalf("ParseLevel(%q): %v", level, err)
+		}
+		if i > 0 && (s.MaxVisits < prev.MaxVisits || s.MaxTime <= prev.MaxTime || s.Temperature >= prev.Temperature) {
+			t.Errorf("%s %+v is not stronger than %s %+v", level, s, levels[i-1], prev)
+		}
+		if s.Temperature < 0 {
+			t.Errorf("%s temperature = %v, want non-negative", level, s.Temperature)
+		}
+		prev = s
+	}
+}
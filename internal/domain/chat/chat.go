@@ -0,0 +1,62 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrEmpty          = errors.New("chat message is empty")
+	ErrTooLong        = errors.New("chat message is too long")
+	ErrUnknownChannel = errors.New("unknown chat channel")
+)
+
+// Каналы чата партии. Канал players видят игроки и зрители, канал kibitz - только зрители,
+// пока партия не завершена.
+const (
+	ChannelPlayers = "players"
+	ChannelKibitz  = "kibitz"
+)
+
+// MaxLength наибольшая длина сообщения в символах.
+const MaxLength = 500
+
+// @name ChatMessage
+type Message struct {
+	Channel string    `json:"channel" bson:"channel"`
+	UserID  string    `json:"user_id" bson:"user_id"`
+	Text    string    `json:"text" bson:"text"`
+	SentAt  time.Time `json:"sent_at" bson:"sent_at"`
+}
+
+// Normalize обрезает пробелы по краям сообщения и проверяет канал и длину.
+func Normalize(channel, text string) (string, error) {
+	if channel != ChannelPlayers && channel != ChannelKibitz {
+		return "", ErrUnknownChannel
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrEmpty
+	}
+	if utf8.RuneCountInString(text) > MaxLength {
+		return "", ErrTooLong
+	}
+	return text, nil
+}
+
+// Visible отбирает сообщения, которые видны игрокам: канал зрителей открывается им
+// только после завершения партии.
+func Visible(messages []Message, finished bool) []Message {
+	if finished {
+		return messages
+	}
+	visible := make([]Message, 0, len(messages))
+	for _, m := range messages {
+		if m.Channel == ChannelPlayers {
+			visible = append(visible, m)
+		}
+	}
+	return visible
+}
@@ -0,0 +1,93 @@
+package chat
+
+import (
+	"strings"
+	"unicode"
+)
+
+// defaultWords корни нецензурных слов, которые маскирует фильтр по умолчанию.
+var defaultWords = []string{
+	"хуй", "хуе", "хуё", "хуя", "пизд", "ебан", "ебал", "ебат", "ёбан", "еблан",
+	"бляд", "блят", "мудак", "мудил", "сука", "суки", "залуп", "гандон",
+	"fuck", "shit", "bitch", "cunt", "asshole",
+}
+
+// prefixes приставки, после которых корень тоже считается началом слова: «наебал», «bullshit».
+// Корень в середине слова без приставки («колебаться», «страхуем») совпадает случайно.
+var prefixes = []string{
+	"на", "за", "от", "отъ", "по", "пона", "вы", "до", "об", "объ", "о", "раз", "разъ", "рас",
+	"при", "пере", "про", "у", "с", "съ", "в", "въ", "под", "подъ", "недо",
+	"mother", "bull", "horse", "dumb",
+}
+
+// maxPrefixes сколько приставок подряд может стоять перед корнем.
+const maxPrefixes = 2
+
+// Filter маскирует звёздочками слова, которые начинаются с запрещённого корня,
+// в том числе после приставок.
+type Filter struct {
+	words []string
+}
+
+// NewFilter создаёт фильтр по списку корней. Пустой список заменяется списком по умолчанию.
+func NewFilter(words []string) *Filter {
+	if len(words) == 0 {
+		words = defaultWords
+	}
+	f := &Filter{words: make([]string, 0, len(words))}
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			f.words = append(f.words, w)
+		}
+	}
+	return f
+}
+
+// Clean возвращает текст, в котором запрещённые слова заменены звёздочками той же длины.
+func (f *Filter) Clean(text string) string {
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if f.banned(string(runes[start:end])) {
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+		}
+		start = end
+	}
+	return string(runes)
+}
+
+func (f *Filter) banned(word string) bool {
+	return f.startsWithRoot(strings.ToLower(word), maxPrefixes)
+}
+
+// startsWithRoot сообщает, что слово начинается с корня сразу или после не более чем
+// depth приставок.
+func (f *Filter) startsWithRoot(word string, depth int) bool {
+	for _, w := range f.words {
+		if strings.HasPrefix(word, w) {
+			return true
+		}
+	}
+	if depth == 0 {
+		return false
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(word, p) && f.startsWithRoot(word[len(p):], depth-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
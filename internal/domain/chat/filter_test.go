@@ -0,0 +1,40 @@
+package chat
+
+import "testing"
+
+func TestFilterClean(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"root at word start", "сука", "****"},
+		{"case insensitive", "Сука!", "****!"},
+		{"after prefix", "наебал", "******"},
+		{"after two prefixes", "понахуярили", "***********"},
+		{"after hard sign prefix", "подъебал", "********"},
+		{"short prefix", "похуй", "*****"},
+		{"english prefix", "bullshit and motherfucker", "******** and ************"},
+		{"punctuation preserved", "ну, блядь... ок", "ну, *****... ок"},
+		{"root inside word", "колебаться", "колебаться"},
+		{"root inside inflected word", "страхуем", "страхуем"},
+		{"root after stem", "психуешь", "психуешь"},
+		{"english root inside word", "Scunthorpe", "Scunthorpe"},
+		{"clean text", "хорошая партия", "хорошая партия"},
+	}
+	f := NewFilter(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Clean(tt.text); got != tt.want {
+				t.Errorf("Clean(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterCustomWords(t *testing.T) {
+	f := NewFilter([]string{" Дурак "})
+	if got, want := f.Clean("вы дураки, а не сука"), "вы ******, а не сука"; got != want {
+		t.Errorf("Clean = %q, want %q", got, want)
+	}
+}
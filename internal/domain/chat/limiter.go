@@ -0,0 +1,57 @@
+package chat
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrRateLimited = errors.New("too many chat messages, try again later")
+
+// RateLimiter ограничивает число сообщений одного пользователя за скользящий интервал.
+type RateLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	interval time.Duration
+	sent     map[string][]time.Time
+	swept    time.Time // когда из sent последний раз убирались молчащие пользователи
+}
+
+// NewRateLimiter разрешает не больше limit сообщений за interval.
+func NewRateLimiter(limit int, interval time.Duration) *RateLimiter {
+	return &RateLimiter{limit: limit, interval: interval, sent: make(map[string][]time.Time)}
+}
+
+// Allow учитывает сообщение пользователя, если лимит не превышен.
+func (l *RateLimiter) Allow(userID string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	recent := l.sent[userID][:0]
+	for _, t := range l.sent[userID] {
+		if now.Sub(t) < l.interval {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= l.limit {
+		l.sent[userID] = recent
+		return false
+	}
+	l.sent[userID] = append(recent, now)
+	return true
+}
+
+// sweep не чаще раза за интервал забывает пользователей, не писавших дольше интервала,
+// чтобы лимитер не хранил всех, кто когда-либо писал в чат.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < l.interval {
+		return
+	}
+	l.swept = now
+	for userID, sent := range l.sent {
+		if len(sent) == 0 || now.Sub(sent[len(sent)-1]) >= l.interval {
+			delete(l.sent, userID)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// send сообщение пользователя user через after от начала и ожидаемый ответ лимитера.
+type send struct {
+	user  string
+	after time.Duration
+	want  bool
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	tests := []struct {
+		name  string
+		sends []send
+	}{
+		{
+			name: "limit within the interval",
+			sends: []send{
+				{"a", 0, true},
+				{"a", time.Second, true},
+				{"a", 2 * time.Second, true},
+				{"a", 3 * time.Second, false},
+				{"a", 9 * time.Second, false},
+			},
+		},
+		{
+			name: "sliding interval frees slots",
+			sends: []send{
+				{"a", 0, true},
+				{"a", time.Second, true},
+				{"a", 2 * time.Second, true},
+				{"a", 10 * time.Second, true},
+				{"a", 10 * time.Second, false},
+				{"a", 11 * time.Second, true},
+			},
+		},
+		{
+			name: "users are counted separately",
+			sends: []send{
+				{"a", 0, true},
+				{"a", 0, true},
+				{"a", 0, true},
+				{"b", 0, true},
+				{"a", 0, false},
+				{"b", 0, true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewRateLimiter(3, 10*time.Second)
+			for i, s := range tt.sends {
+				if got := l.Allow(s.user, start.Add(s.after)); got != s.want {
+					t.Fatalf("send %d: Allow(%s, +%v) = %v, want %v", i, s.user, s.after, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterForgetsSilentUsers(t *testing.T) {
+	l := NewRateLimiter(3, 10*time.Second)
+	for _, user := range []string{"a", "b", "c"} {
+		l.Allow(user, start)
+	}
+	l.Allow("a", start.Add(5*time.Second))
+
+	l.Allow("d", start.Add(12*time.Second))
+	if _, ok := l.sent["b"]; ok {
+		t.Error("silent user b is still tracked")
+	}
+	if _, ok := l.sent["a"]; !ok {
+		t.Error("recent user a is forgotten")
+	}
+	if len(l.sent) != 2 {
+		t.Errorf("tracked %d users, want 2", len(l.sent))
+	}
+}
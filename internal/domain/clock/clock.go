@@ -0,0 +1,308 @@
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"team_exe/internal/domain/board"
+)
+
+var (
+	ErrUnknownTimeControl = errors.New("unknown time control")
+	ErrBadTimeControl     = errors.New("invalid time control settings")
+)
+
+// Виды контроля времени.
+const (
+	TypeNone     = "none"
+	TypeAbsolute = "absolute"
+	TypeFischer  = "fischer"
+	TypeByoyomi  = "byoyomi"
+	TypeCanadian = "canadian"
+)
+
+// TimeControl настройки контроля времени партии. Все длительности задаются в секундах.
+type TimeControl struct {
+	Type       string `json:"type" bson:"type"`                                   // none, absolute, fischer, byoyomi, canadian
+	MainTime   int    `json:"main_time" bson:"main_time"`                         // основное время
+	Increment  int    `json:"increment,omitempty" bson:"increment,omitempty"`     // добавка за ход (fischer)
+	MaxTime    int    `json:"max_time,omitempty" bson:"max_time,omitempty"`       // предел накопленного времени (fischer), 0 - без предела
+	Periods    int    `json:"periods,omitempty" bson:"periods,omitempty"`         // число периодов бёёми
+	PeriodTime int    `json:"period_time,omitempty" bson:"period_time,omitempty"` // длина периода бёёми или канадского периода
+	Stones     int    `json:"stones,omitempty" bson:"stones,omitempty"`           // ходов на канадский период
+}
+
+// Enabled сообщает, ограничено ли время в партии.
+func (tc TimeControl) Enabled() bool {
+	return tc.Type != "" && tc.Type != TypeNone
+}
+
+// Validate проверяет, что настройки соответствуют виду контроля.
+func (tc TimeControl) Validate() error {
+	if tc.MainTime < 0 || tc.Increment < 0 || tc.MaxTime < 0 || tc.Periods < 0 || tc.PeriodTime < 0 || tc.Stones < 0 {
+		return ErrBadTimeControl
+	}
+	switch tc.Type {
+	case "", TypeNone:
+		return nil
+	case TypeAbsolute:
+		if tc.MainTime == 0 {
+			return ErrBadTimeControl
+		}
+	case TypeFischer:
+		if tc.MainTime == 0 || tc.MaxTime > 0 && tc.MaxTime < tc.MainTime {
+			return ErrBadTimeControl
+		}
+	case TypeByoyomi:
+		if tc.Periods == 0 || tc.PeriodTime == 0 {
+			return ErrBadTimeControl
+		}
+	case TypeCanadian:
+		if tc.Stones == 0 || tc.PeriodTime == 0 {
+			return ErrBadTimeControl
+		}
+	default:
+		return ErrUnknownTimeControl
+	}
+	return nil
+}
+
+// Overtime описывает дополнительное время для свойства OT в SGF, например "5x30 byo-yomi".
+func (tc TimeControl) Overtime() string {
+	switch tc.Type {
+	case TypeFischer:
+		return fmt.Sprintf("%d fischer", tc.Increment)
+	case TypeByoyomi:
+		return fmt.Sprintf("%dx%d byo-yomi", tc.Periods, tc.PeriodTime)
+	case TypeCanadian:
+		return fmt.Sprintf("%d/%d canadian", tc.Stones, tc.PeriodTime)
+	}
+	return ""
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+// player остаток времени одного игрока.
+type player struct {
+	main    time.Duration // основное время
+	periods int           // оставшиеся периоды бёёми
+	period  time.Duration // остаток текущего периода бёёми или канадского периода
+	stones  int           // ходов, которые осталось сделать в канадском периоде
+}
+
+// Clock шахматные часы партии. Идут часы только того цвета, чей ход.
+type Clock struct {
+	control TimeControl
+	players [3]player
+	running board.Color
+	since   time.Time
+}
+
+// New создаёт остановленные часы с полным запасом времени у обоих игроков.
+func New(tc TimeControl) *Clock {
+	c := &Clock{control: tc}
+	for _, color := range []board.Color{board.Black, board.White} {
+		c.players[color] = player{
+			main:    seconds(tc.MainTime),
+			periods: tc.Periods,
+			period:  seconds(tc.PeriodTime),
+			stones:  tc.Stones,
+		}
+	}
+	return c
+}
+
+// Control возвращает настройки контроля времени.
+func (c *Clock) Control() TimeControl {
+	return c.control
+}
+
+// Running возвращает цвет, чьи часы идут, или board.Empty, если часы остановлены.
+func (c *Clock) Running() board.Color {
+	return c.running
+}
+
+// Start запускает часы игрока color.
+func (c *Clock) Start(color board.Color, now time.Time) {
+	c.running = color
+	c.since = now
+}
+
+// Switch останавливает часы игрока, который сделал ход, учитывает добавку или
+// дополнительное время и запускает часы игрока next. Возвращает false, если
+// время ходившего истекло раньше, чем был сделан ход.
+func (c *Clock) Switch(next board.Color, now time.Time) bool {
+	if c.running == next {
+		return true
+	}
+	if c.running == board.Empty {
+		c.Start(next, now)
+		return true
+	}
+	p := &c.players[c.running]
+	ok := p.spend(c.control, now.Sub(c.since))
+	if ok {
+		p.moved(c.control)
+	}
+	c.Start(next, now)
+	return ok
+}
+
+// Stop останавливает часы, списывая время текущему игроку.
+func (c *Clock) Stop(now time.Time) {
+	if c.running == board.Empty {
+		return
+	}
+	c.players[c.running].spend(c.control, now.Sub(c.since))
+	c.running = board.Empty
+}
+
+// Flagged возвращает цвет игрока, у которого истекло время, если такой есть.
+func (c *Clock) Flagged(now time.Time) (board.Color, bool) {
+	if c.running == board.Empty {
+		return board.Empty, false
+	}
+	if c.Remaining(c.running, now) > 0 {
+		return board.Empty, false
+	}
+	return c.running, true
+}
+
+// Remaining возвращает, сколько времени осталось игроку color до падения флага.
+func (c *Clock) Remaining(color board.Color, now time.Time) time.Duration {
+	p := c.players[color]
+	left := p.main
+	switch c.control.Type {
+	case TypeByoyomi:
+		left += time.Duration(p.periods) * seconds(c.control.PeriodTime)
+	case TypeCanadian:
+		left += p.period
+	}
+	if color == c.running {
+		left -= now.Sub(c.since)
+	}
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// spend списывает с игрока время elapsed. Возвращает false, если его не хватило.
+func (p *player) spend(tc TimeControl, elapsed time.Duration) bool {
+	if elapsed <= p.main {
+		p.main -= elapsed
+		return true
+	}
+	elapsed -= p.main
+	p.main = 0
+
+	switch tc.Type {
+	case TypeByoyomi:
+		// период, в который игрок уложился, не сгорает
+		period := seconds(tc.PeriodTime)
+		lost := int(elapsed / period)
+		if lost >= p.periods {
+			p.periods = 0
+			p.period = 0
+			return false
+		}
+		p.periods -= lost
+		p.period = period - elapsed%period
+		return true
+	case TypeCanadian:
+		if elapsed >= p.period {
+			p.period = 0
+			return false
+		}
+		p.period -= elapsed
+		return true
+	}
+	return false
+}
+
+// moved начисляет игроку добавку после сделанного вовремя хода.
+func (p *player) moved(tc TimeControl) {
+	switch tc.Type {
+	case TypeFischer:
+		p.main += seconds(tc.Increment)
+		if limit := seconds(tc.MaxTime); limit > 0 && p.main > limit {
+			p.main = limit
+		}
+	case TypeByoyomi:
+		p.period = seconds(tc.PeriodTime)
+	case TypeCanadian:
+		if p.main > 0 {
+			return
+		}
+		p.stones--
+		if p.stones <= 0 {
+			p.stones = tc.Stones
+			p.period = seconds(tc.PeriodTime)
+		}
+	}
+}
+
+// PlayerState остаток времени игрока для отправки клиенту.
+type PlayerState struct {
+	MainMs   int64 `json:"main_ms" bson:"main_ms"`
+	Periods  int   `json:"periods,omitempty" bson:"periods,omitempty"`
+	PeriodMs int64 `json:"period_ms,omitempty" bson:"period_ms,omitempty"`
+	Stones   int   `json:"stones,omitempty" bson:"stones,omitempty"`
+}
+
+// State состояние часов на момент отправки.
+type State struct {
+	Black   PlayerState `json:"black" bson:"black"`
+	White   PlayerState `json:"white" bson:"white"`
+	Running string      `json:"running,omitempty" bson:"running,omitempty"` // цвет, чьи часы идут
+}
+
+// Restore восстанавливает часы из сохранённого состояния. Часы игрока, чей был ход,
+// запускаются с момента now, так что время простоя сервера игрокам не засчитывается.
+func Restore(tc TimeControl, state State, now time.Time) *Clock {
+	c := &Clock{control: tc}
+	c.players[board.Black] = state.Black.player()
+	c.players[board.White] = state.White.player()
+	if running, err := board.ParseColor(state.Running); err == nil {
+		c.Start(running, now)
+	}
+	return c
+}
+
+func (s PlayerState) player() player {
+	return player{
+		main:    time.Duration(s.MainMs) * time.Millisecond,
+		periods: s.Periods,
+		period:  time.Duration(s.PeriodMs) * time.Millisecond,
+		stones:  s.Stones,
+	}
+}
+
+// State возвращает состояние часов на момент now с учётом уже идущего хода.
+func (c *Clock) State(now time.Time) State {
+	return State{
+		Black:   c.playerState(board.Black, now),
+		White:   c.playerState(board.White, now),
+		Running: c.running.String(),
+	}
+}
+
+func (c *Clock) playerState(color board.Color, now time.Time) PlayerState {
+	p := c.players[color]
+	if color == c.running {
+		p.spend(c.control, now.Sub(c.since))
+	}
+	state := PlayerState{MainMs: p.main.Milliseconds()}
+	switch c.control.Type {
+	case TypeByoyomi:
+		state.Periods = p.periods
+		state.PeriodMs = p.period.Milliseconds()
+	case TypeCanadian:
+		state.PeriodMs = p.period.Milliseconds()
+		state.Stones = p.stones
+	}
+	return state
+}
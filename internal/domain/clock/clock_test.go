@@ -0,0 +1,145 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"team_exe/internal/domain/board"
+)
+
+// move ход чёрных, обдумывавшийся think, на который белые сразу отвечают.
+type move struct {
+	think time.Duration
+	ok    bool
+	want  PlayerState // остаток чёрных после хода
+}
+
+// playBlack по очереди делает ходы moves и сверяет остаток времени чёрных после каждого.
+func playBlack(t *testing.T, tc TimeControl, moves []move) {
+	t.Helper()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := New(tc)
+	c.Start(board.Black, now)
+	for i, m := range moves {
+		now = now.Add(m.think)
+		if ok := c.Switch(board.White, now); ok != m.ok {
+			t.Fatalf("move %d: Switch = %v, want %v", i+1, ok, m.ok)
+		}
+		if got := c.State(now).Black; got != m.want {
+			t.Fatalf("move %d: black state = %+v, want %+v", i+1, got, m.want)
+		}
+		c.Switch(board.Black, now)
+	}
+}
+
+func TestByoyomi(t *testing.T) {
+	tc := TimeControl{Type: TypeByoyomi, MainTime: 10, Periods: 3, PeriodTime: 5}
+	tests := []struct {
+		name  string
+		moves []move
+	}{
+		{
+			name: "main time is spent first",
+			moves: []move{
+				{think: 4 * time.Second, ok: true, want: PlayerState{MainMs: 6000, Periods: 3, PeriodMs: 5000}},
+			},
+		},
+		{
+			name: "move within a period keeps it",
+			moves: []move{
+				{think: 14 * time.Second, ok: true, want: PlayerState{Periods: 3, PeriodMs: 5000}},
+				{think: 4 * time.Second, ok: true, want: PlayerState{Periods: 3, PeriodMs: 5000}},
+			},
+		},
+		{
+			name: "overrun periods are lost",
+			moves: []move{
+				{think: 10 * time.Second, ok: true, want: PlayerState{Periods: 3, PeriodMs: 5000}},
+				{think: 11 * time.Second, ok: true, want: PlayerState{Periods: 1, PeriodMs: 5000}},
+			},
+		},
+		{
+			name: "last period lost",
+			moves: []move{
+				{think: 20 * time.Second, ok: true, want: PlayerState{Periods: 1, PeriodMs: 5000}},
+				{think: 5 * time.Second, ok: false, want: PlayerState{}},
+			},
+		},
+		{
+			name: "all periods lost in one move",
+			moves: []move{
+				{think: 25 * time.Second, ok: false, want: PlayerState{}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playBlack(t, tc, tt.moves)
+		})
+	}
+}
+
+func TestCanadian(t *testing.T) {
+	tc := TimeControl{Type: TypeCanadian, MainTime: 5, PeriodTime: 10, Stones: 2}
+	tests := []struct {
+		name  string
+		moves []move
+	}{
+		{
+			name: "stones are counted only in overtime",
+			moves: []move{
+				{think: 2 * time.Second, ok: true, want: PlayerState{MainMs: 3000, PeriodMs: 10000, Stones: 2}},
+				{think: 4 * time.Second, ok: true, want: PlayerState{PeriodMs: 9000, Stones: 1}},
+			},
+		},
+		{
+			name: "period resets after the last stone",
+			moves: []move{
+				{think: 6 * time.Second, ok: true, want: PlayerState{PeriodMs: 9000, Stones: 1}},
+				{think: 4 * time.Second, ok: true, want: PlayerState{PeriodMs: 10000, Stones: 2}},
+				{think: 9 * time.Second, ok: true, want: PlayerState{PeriodMs: 1000, Stones: 1}},
+			},
+		},
+		{
+			name: "period runs out before the stones are played",
+			moves: []move{
+				{think: 6 * time.Second, ok: true, want: PlayerState{PeriodMs: 9000, Stones: 1}},
+				{think: 9 * time.Second, ok: false, want: PlayerState{Stones: 1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playBlack(t, tc, tt.moves)
+		})
+	}
+}
+
+func TestFlagged(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		tc   TimeControl
+		fall time.Duration // когда падает флаг чёрных
+	}{
+		{name: "absolute", tc: TimeControl{Type: TypeAbsolute, MainTime: 60}, fall: time.Minute},
+		{name: "byoyomi", tc: TimeControl{Type: TypeByoyomi, MainTime: 10, Periods: 3, PeriodTime: 5}, fall: 25 * time.Second},
+		{name: "canadian", tc: TimeControl{Type: TypeCanadian, MainTime: 5, PeriodTime: 10, Stones: 2}, fall: 15 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(tt.tc)
+			c.Start(board.Black, start)
+			if _, flagged := c.Flagged(start.Add(tt.fall - time.Millisecond)); flagged {
+				t.Fatal("flag fell before time ran out")
+			}
+			color, flagged := c.Flagged(start.Add(tt.fall))
+			if !flagged || color != board.Black {
+				t.Fatalf("Flagged = %v, %v, want %v, true", color, flagged, board.Black)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package coord
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrBadCoordinate = errors.New("malformed coordinate")
+	ErrOutOfRange    = errors.New("coordinate is outside of the board")
+)
+
+// DefaultBoardSize размер доски, если он не известен из партии.
+const DefaultBoardSize = 19
+
+// gtpColumns буквы столбцов в GTP: буква I пропускается, чтобы не путать её с J.
+const gtpColumns = "ABCDEFGHJKLMNOPQRSTUVWXYZ"
+
+// Point пункт доски или пас. X - столбец, Y - строка, отсчёт с нуля от левого верхнего угла.
+type Point struct {
+	X    int
+	Y    int
+	Pass bool
+}
+
+// PassPoint обозначает пас.
+var PassPoint = Point{Pass: true}
+
+// Parse разбирает координату в любой из поддерживаемых нотаций:
+// SGF ("dd"), GTP ("D4"), числовой ("3,15") или "pass". Пас передаётся только явно:
+// пустое значение означает пас лишь внутри SGF, а здесь считается ошибкой.
+func Parse(s string, size int) (Point, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return Point{}, ErrBadCoordinate
+	case strings.EqualFold(s, "pass"):
+		return PassPoint, nil
+	case strings.Contains(s, ","):
+		return ParseNumeric(s, size)
+	case len(s) >= 2 && isDigit(s[1]):
+		return ParseGTP(s, size)
+	}
+	return ParseSGF(s, size)
+}
+
+// ParseSGF разбирает координату SGF. Пустое значение, а на досках до 19x19 и "tt", означает пас.
+func ParseSGF(s string, size int) (Point, error) {
+	if s == "" || (s == "tt" && size <= 19) {
+		return PassPoint, nil
+	}
+	if len(s) != 2 {
+		return Point{}, fmt.Errorf("%w: %q", ErrBadCoordinate, s)
+	}
+	x, okX := sgfIndex(s[0])
+	y, okY := sgfIndex(s[1])
+	if !okX || !okY {
+		return Point{}, fmt.Errorf("%w: %q", ErrBadCoordinate, s)
+	}
+	return checked(Point{X: x, Y: y}, size)
+}
+
+// ParseGTP разбирает вершину GTP: буква столбца без I и номер строки, считая снизу.
+func ParseGTP(s string, size int) (Point, error) {
+	if strings.EqualFold(s, "pass") {
+		return PassPoint, nil
+	}
+	if len(s) < 2 {
+		return Point{}, fmt.Errorf("%w: %q", ErrBadCoordinate, s)
+	}
+	x := strings.IndexByte(gtpColumns, toUpper(s[0]))
+	row, err := strconv.Atoi(s[1:])
+	if x == -1 || err != nil {
+		return Point{}, fmt.Errorf("%w: %q", ErrBadCoordinate, s)
+	}
+	return checked(Point{X: x, Y: size - row}, size)
+}
+
+// ParseNumeric разбирает пару "столбец,строка" с отсчётом с нуля от левого верхнего угла.
+func ParseNumeric(s string, size int) (Point, error) {
+	xs, ys, ok := strings.Cut(s, ",")
+	if !ok {
+		return Point{}, fmt.Errorf("%w: %q", ErrBadCoordinate, s)
+	}
+	x, errX := strconv.Atoi(strings.TrimSpace(xs))
+	y, errY := strconv.Atoi(strings.TrimSpace(ys))
+	if errX != nil || errY != nil {
+		return Point{}, fmt.Errorf("%w: %q", ErrBadCoordinate, s)
+	}
+	return checked(Point{X: x, Y: y}, size)
+}
+
+// OnBoard проверяет, что пункт лежит на доске размера size. Пас лежит на любой доске.
+func (p Point) OnBoard(size int) bool {
+	return p.Pass || (p.X >= 0 && p.Y >= 0 && p.X < size && p.Y < size)
+}
+
+// SGF возвращает координату в нотации SGF, пас записывается пустой строкой.
+func (p Point) SGF() string {
+	if p.Pass {
+		return ""
+	}
+	return string([]byte{sgfLetter(p.X), sgfLetter(p.Y)})
+}
+
+// GTP возвращает вершину GTP для доски размера size.
+func (p Point) GTP(size int) string {
+	if p.Pass {
+		return "pass"
+	}
+	return string(gtpColumns[p.X]) + strconv.Itoa(size-p.Y)
+}
+
+// Numeric возвращает координату в виде "столбец,строка".
+func (p Point) Numeric() string {
+	if p.Pass {
+		return "pass"
+	}
+	return strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y)
+}
+
+func checked(p Point, size int) (Point, error) {
+	if !p.OnBoard(size) {
+		return Point{}, ErrOutOfRange
+	}
+	return p, nil
+}
+
+// sgfIndex переводит букву SGF в индекс: a-z дают 0-25, A-Z - 26-51.
+func sgfIndex(c byte) (int, bool) {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a'), true
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 26, true
+	}
+	return 0, false
+}
+
+func sgfLetter(i int) byte {
+	if i < 26 {
+		return byte('a' + i)
+	}
+	return byte('A' + i - 26)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func toUpper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
@@ -0,0 +1,112 @@
+package coord
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		size    int
+		want    Point
+		wantErr error
+	}{
+		{name: "sgf", s: "dp", size: 19, want: Point{X: 3, Y: 15}},
+		{name: "sgf corner", s: "aa", size: 19, want: Point{X: 0, Y: 0}},
+		{name: "sgf tt is pass on 19x19", s: "tt", size: 19, want: PassPoint},
+		{name: "sgf tt is a point on larger boards", s: "tt", size: 21, want: Point{X: 19, Y: 19}},
+		{name: "sgf outside of the board", s: "jj", size: 9, wantErr: ErrOutOfRange},
+		{name: "gtp", s: "D4", size: 19, want: Point{X: 3, Y: 15}},
+		{name: "gtp lower case", s: "d4", size: 19, want: Point{X: 3, Y: 15}},
+		{name: "gtp skips I", s: "J10", size: 19, want: Point{X: 8, Y: 9}},
+		{name: "gtp has no I column", s: "I5", size: 19, wantErr: ErrBadCoordinate},
+		{name: "gtp top right", s: "T19", size: 19, want: Point{X: 18, Y: 0}},
+		{name: "gtp bottom left", s: "A1", size: 9, want: Point{X: 0, Y: 8}},
+		{name: "gtp row outside of the board", s: "A10", size: 9, wantErr: ErrOutOfRange},
+		{name: "gtp column outside of the board", s: "K1", size: 9, wantErr: ErrOutOfRange},
+		{name: "numeric", s: "3,15", size: 19, want: Point{X: 3, Y: 15}},
+		{name: "numeric with spaces", s: " 3 , 15 ", size: 19, want: Point{X: 3, Y: 15}},
+		{name: "numeric outside of the board", s: "19,0", size: 19, wantErr: ErrOutOfRange},
+		{name: "numeric negative", s: "-1,0", size: 19, wantErr: ErrOutOfRange},
+		{name: "numeric not a number", s: "a,1", size: 19, wantErr: ErrBadCoordinate},
+		{name: "pass", s: "pass", size: 19, want: PassPoint},
+		{name: "pass upper case", s: "PASS", size: 9, want: PassPoint},
+		{name: "empty is not a pass", s: "", size: 19, wantErr: ErrBadCoordinate},
+		{name: "blank is not a pass", s: "  ", size: 19, wantErr: ErrBadCoordinate},
+		{name: "single letter", s: "a", size: 19, wantErr: ErrBadCoordinate},
+		{name: "garbage", s: "d$", size: 19, wantErr: ErrBadCoordinate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.s, tt.size)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.s, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSGFEmptyIsPass(t *testing.T) {
+	got, err := ParseSGF("", 19)
+	if err != nil {
+		t.Fatalf("ParseSGF: %v", err)
+	}
+	if got != PassPoint {
+		t.Errorf("ParseSGF(\"\") = %+v, want pass", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Point
+		size    int
+		sgf     string
+		gtp     string
+		numeric string
+	}{
+		{name: "star point", p: Point{X: 3, Y: 15}, size: 19, sgf: "dp", gtp: "D4", numeric: "3,15"},
+		{name: "column after I", p: Point{X: 8, Y: 0}, size: 19, sgf: "ia", gtp: "J19", numeric: "8,0"},
+		{name: "small board", p: Point{X: 4, Y: 4}, size: 9, sgf: "ee", gtp: "E5", numeric: "4,4"},
+		{name: "pass", p: PassPoint, size: 19, sgf: "", gtp: "pass", numeric: "pass"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.SGF(); got != tt.sgf {
+				t.Errorf("SGF = %q, want %q", got, tt.sgf)
+			}
+			if got := tt.p.GTP(tt.size); got != tt.gtp {
+				t.Errorf("GTP = %q, want %q", got, tt.gtp)
+			}
+			if got := tt.p.Numeric(); got != tt.numeric {
+				t.Errorf("Numeric = %q, want %q", got, tt.numeric)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, size := range []int{9, 13, 19, 25} {
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				p := Point{X: x, Y: y}
+				for _, s := range []string{p.SGF(), p.GTP(size), p.Numeric()} {
+					got, err := Parse(s, size)
+					if err != nil {
+						t.Fatalf("size %d: Parse(%q): %v", size, s, err)
+					}
+					if got != p {
+						t.Fatalf("size %d: Parse(%q) = %+v, want %+v", size, s, got, p)
+					}
+				}
+			}
+		}
+	}
+}
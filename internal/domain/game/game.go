@@ -1,60 +1,174 @@
 package game
 
 import (
-	"github.com/gorilla/websocket"
+	"strconv"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/chat"
+	"team_exe/internal/domain/clock"
+	"team_exe/internal/domain/rank"
 	"time"
 )
 
 // @name Game
 type Game struct {
-	GameKeySecret string          `json:"game_key" bson:"game_key"` // уникальный ключ
-	GameKeyPublic string          `json:"game_key_public" bson:"game_key_public"`
-	Users         []*GameUser     `json:"users" bson:"users"`
-	CreatedAt     time.Time       `json:"created_at" bson:"created_at"`
-	StartedAt     *time.Time      `json:"started_at,omitempty" bson:"started_at,omitempty"`
-	Status        string          `json:"status" bson:"status"`
-	BoardSize     int             `json:"board_size" bson:"board_size"`
-	CurrentTurn   string          `json:"current_turn" bson:"current_turn"`
-	Moves         []Move          `json:"moves" bson:"moves"`
-	WhoIsNext     string          `json:"who_is_next" bson:"who_is_next"` // color
-	PlayerBlack   string          `json:"player_black" bson:"player_black"`
-	PlayerWhite   string          `json:"player_white" bson:"player_white"`
-	PlayerBlackWS *websocket.Conn `json:"-"`
-	PlayerWhiteWS *websocket.Conn `json:"-"`
-	Komi          float64         `json:"komi" bson:"komi"`
-	Sgf           string          `json:"sgf" bson:"sgf"`
+	GameKeySecret  string            `json:"game_key" bson:"game_key"` // уникальный ключ
+	GameKeyPublic  string            `json:"game_key_public" bson:"game_key_public"`
+	Users          []*GameUser       `json:"users" bson:"users"`
+	CreatedAt      time.Time         `json:"created_at" bson:"created_at"`
+	StartedAt      *time.Time        `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	Status         string            `json:"status" bson:"status"`
+	BoardSize      int               `json:"board_size" bson:"board_size"`
+	CurrentTurn    string            `json:"current_turn" bson:"current_turn"`
+	Moves          []Move            `json:"moves" bson:"moves"`
+	WhoIsNext      string            `json:"who_is_next" bson:"who_is_next"` // color
+	PlayerBlack    string            `json:"player_black" bson:"player_black"`
+	PlayerWhite    string            `json:"player_white" bson:"player_white"`
+	Komi           float64           `json:"komi" bson:"komi"`
+	Rules          string            `json:"rules" bson:"rules"`
+	Handicap       int               `json:"handicap" bson:"handicap"`
+	HandicapType   string            `json:"handicap_type" bson:"handicap_type"`
+	HandicapStones []string          `json:"handicap_stones" bson:"handicap_stones"` // поставленные камни форы в SGF-нотации
+	Result         *Result           `json:"result,omitempty" bson:"result,omitempty"`
+	Sgf            string            `json:"sgf" bson:"sgf"`
+	Board          *board.Board      `json:"-" bson:"-"`                        // текущая позиция, восстанавливается из Moves
+	DeadStones     []string          `json:"-" bson:"dead_stones,omitempty"`    // камни, помеченные мёртвыми при подсчёте, в SGF-нотации
+	ScoreAccepted  map[string]bool   `json:"-" bson:"score_accepted,omitempty"` // цвета игроков, согласившихся с пометкой мёртвых камней
+	Rated          bool              `json:"rated" bson:"rated"`
+	AllowUndo      bool              `json:"allow_undo" bson:"allow_undo"`
+	UndoLimit      int               `json:"undo_limit" bson:"undo_limit"` // 0 - без ограничения
+	UndosUsed      map[string]int    `json:"undos_used" bson:"undos_used"` // принятые отмены по цветам
+	UndoRequest    *UndoRequest      `json:"-" bson:"-"`
+	TimeControl    clock.TimeControl `json:"time_control" bson:"time_control"`
+	Clock          *clock.Clock      `json:"-" bson:"-"`                     // часы идут в памяти сервера
+	ClockState     *clock.State      `json:"-" bson:"clock_state,omitempty"` // состояние часов после последнего хода
+	IsPublic       bool              `json:"is_public" bson:"is_public"`
+	MaxSpectators  int               `json:"max_spectators" bson:"max_spectators"`           // 0 - без ограничения
+	Chat           []chat.Message    `json:"-" bson:"chat,omitempty"`                        // сообщения обоих каналов, игрокам отдаются через chat.Visible
+	BotLevel       string            `json:"bot_level,omitempty" bson:"bot_level,omitempty"` // уровень KataGo в партии против бота
+}
+
+// BotPlayerID идентификатор KataGo в полях игроков партии против бота.
+const BotPlayerID = "katago"
+
+// BotNickname имя бота в информации о партии.
+const BotNickname = "KataGo"
+
+// BotColor возвращает цвет, которым в партии играет бот, или board.Empty, если играют люди.
+func (g *Game) BotColor() board.Color {
+	switch BotPlayerID {
+	case g.PlayerBlack:
+		return board.Black
+	case g.PlayerWhite:
+		return board.White
+	}
+	return board.Empty
+}
+
+// BotPosition возвращает текущую позицию партии для запроса хода у KataGo.
+// Камни форы передаются начальной расстановкой.
+func (g *Game) BotPosition() BotPosition {
+	setup := make([]Move, 0, len(g.HandicapStones))
+	for _, stone := range g.HandicapStones {
+		setup = append(setup, Move{Color: board.Black.String(), Coordinates: stone})
+	}
+	moves := make([]Move, 0, len(g.Moves))
+	for _, m := range g.Moves {
+		// в партии пас хранится пустой координатой SGF
+		if m.Coordinates == "" {
+			m.Coordinates = "pass"
+		}
+		moves = append(moves, m)
+	}
+	return BotPosition{
+		BoardSize: g.BoardSize,
+		Komi:      g.Komi,
+		Rules:     g.Rules,
+		Setup:     setup,
+		Moves:     moves,
+		ToMove:    g.WhoIsNext,
+	}
+}
+
+// UndoRequest запрос на отмену хода, ожидающий ответа соперника.
+type UndoRequest struct {
+	Color string // цвет игрока, попросившего отмену
 }
 
 // @name GameFromArchive
 type GameFromArchive struct {
-	BlackPlayer string    `bson:"black_player"`
-	WhitePlayer string    `bson:"white_player"`
-	Date        time.Time `bson:"date"`
-	Moves       []Move    `bson:"moves"`
-	Komi        float64   `bson:"komi"`
-	Rules       string    `bson:"rules"`
-	Result      Result    `bson:"result"`
-	BlackRank   string    `bson:"black_rank"`
-	WhiteRank   string    `bson:"white_rank"`
-	Event       string    `bson:"event"`
-	BoardSize   int       `bson:"board_size"`
-	Sgf         string    `bson:"sgf"`
+	BlackPlayer   string     `bson:"black_player"`
+	WhitePlayer   string     `bson:"white_player"`
+	Date          time.Time  `bson:"date"`
+	Moves         []Move     `bson:"moves"`
+	Komi          float64    `bson:"komi"`
+	Rules         string     `bson:"rules"`
+	Result        Result     `bson:"result"`
+	BlackRank     string     `bson:"black_rank"`
+	WhiteRank     string     `bson:"white_rank"`
+	BlackRankInfo *rank.Rank `bson:"-"` // разобранный BlackRank, nil если запись не распознана
+	WhiteRankInfo *rank.Rank `bson:"-"`
+	Event         string     `bson:"event"`
+	BoardSize     int        `bson:"board_size"`
+	Sgf           string     `bson:"sgf"`
+}
+
+// ParseRanks разбирает записанные в архиве ранги игроков, чтобы их можно было сравнивать.
+func (g *GameFromArchive) ParseRanks() {
+	if r, err := rank.Parse(g.BlackRank); err == nil {
+		g.BlackRankInfo = &r
+	}
+	if r, err := rank.Parse(g.WhiteRank); err == nil {
+		g.WhiteRankInfo = &r
+	}
 }
 
 // @name Result
 type Result struct {
 	WinColor  string  `bson:"win_color"`
 	PointDiff float64 `bson:"point_diff"`
+	Reason    string  `bson:"reason,omitempty"`
+}
+
+const (
+	ResultReasonResign   = "resign"
+	ResultReasonScore    = "score"
+	ResultReasonTime     = "time"
+	ResultReasonAbandon  = "abandon"  // игрок покинул партию и не вернулся
+	ResultReasonAnnulled = "annulled" // партия отменена без победителя и не идёт в статистику
+)
+
+// Annulled сообщает, что партия аннулирована.
+func (r Result) Annulled() bool {
+	return r.Reason == ResultReasonAnnulled
+}
+
+// SgfString возвращает результат в формате свойства RE: "W+R", "B+T", "W+F", "B+3.5",
+// "0" при ничьей или "Void" для аннулированной партии.
+func (r Result) SgfString() string {
+	if r.Annulled() {
+		return "Void"
+	}
+	if r.WinColor == "" {
+		return "0"
+	}
+	switch r.Reason {
+	case ResultReasonResign:
+		return r.WinColor + "+R"
+	case ResultReasonTime:
+		return r.WinColor + "+T"
+	case ResultReasonAbandon:
+		return r.WinColor + "+F"
+	}
+	return r.WinColor + "+" + strconv.FormatFloat(r.PointDiff, 'f', -1, 64)
 }
 
 // @name GameUser
 type GameUser struct {
-	ID     string          `json:"id" bson:"id"`
-	Role   string          `json:"role" bson:"role"`
-	Color  string          `json:"color" bson:"color"`
-	Rating float64         `json:"rating" bson:"rating"`
-	Score  float64         `json:"score" bson:"score"`
-	WS     *websocket.Conn `json:"-"`
+	ID     string  `json:"id" bson:"id"`
+	Role   string  `json:"role" bson:"role"`
+	Color  string  `json:"color" bson:"color"`
+	Rating float64 `json:"rating" bson:"rating"`
+	Score  float64 `json:"score" bson:"score"`
 }
 
 // @name GameCreateResponse
@@ -68,15 +182,84 @@ type GameJoinRequest struct {
 	Role          string `json:"role" bson:"role"`
 }
 
+// Роли пользователя в GameJoinRequest.
+const (
+	RolePlayer    = "player"
+	RoleSpectator = "spectator"
+)
+
 // @name GameLeaveRequest
 type GameLeaveRequest struct {
 	GameKeyPublic string `json:"public_key" bson:"public_key"`
 }
 
+// @name GameAction
+type GameAction struct {
+	Type string `json:"type,omitempty"` // move (по умолчанию), pass, resign, toggle_dead, accept_score, undo_request, undo_accept, undo_decline, chat
+	Move
+	Channel string `json:"channel,omitempty"` // канал сообщения чата: players или kibitz
+	Text    string `json:"text,omitempty"`    // текст сообщения чата
+}
+
+const (
+	ActionMove        = "move"
+	ActionPass        = "pass"
+	ActionResign      = "resign"
+	ActionToggleDead  = "toggle_dead"
+	ActionAcceptScore = "accept_score"
+	ActionUndoRequest = "undo_request"
+	ActionUndoAccept  = "undo_accept"
+	ActionUndoDecline = "undo_decline"
+	ActionChat        = "chat"
+)
+
+const (
+	EventUndoRequested = "undo_requested"
+	EventUndoAccepted  = "undo_accepted"
+	EventUndoDeclined  = "undo_declined"
+	EventSpectators    = "spectators" // изменилось число зрителей
+	EventSnapshot      = "snapshot"
+	EventChat          = "chat"
+)
+
 // @name GameStateResponse
 type GameStateResponse struct {
-	Move Move   `json:"move"`
-	SGF  string `json:"sgf"`
+	Move          Move          `json:"move"`
+	SGF           string        `json:"sgf"`
+	Status        string        `json:"status,omitempty"`
+	Result        string        `json:"result,omitempty"`
+	DeadStones    []string      `json:"dead_stones,omitempty"`
+	ScoreAccepted []string      `json:"score_accepted,omitempty"`
+	Score         *board.Score  `json:"score,omitempty"`
+	Event         string        `json:"event,omitempty"`
+	Moves         []Move        `json:"moves,omitempty"` // ходы восстановленной позиции после отмены
+	WhoIsNext     string        `json:"who_is_next,omitempty"`
+	Clock         *clock.State  `json:"clock,omitempty"`
+	Spectators    *int          `json:"spectators,omitempty"`
+	Chat          *chat.Message `json:"chat,omitempty"`
+}
+
+// @name GameSnapshot
+// GameSnapshot полное состояние партии, которое клиент получает при подключении и по запросу resync.
+type GameSnapshot struct {
+	Event       string          `json:"event"` // snapshot
+	Status      string          `json:"status"`
+	BoardSize   int             `json:"board_size"`
+	Komi        float64         `json:"komi"`
+	Rules       string          `json:"rules"`
+	PlayerBlack string          `json:"player_black"`
+	PlayerWhite string          `json:"player_white"`
+	Moves       []Move          `json:"moves"`
+	WhoIsNext   string          `json:"who_is_next"`
+	SGF         string          `json:"sgf"`
+	Result      string          `json:"result,omitempty"`
+	Clock       *clock.State    `json:"clock,omitempty"`
+	Spectators  int             `json:"spectators"`
+	Chat        []chat.Message  `json:"chat"`
+	Board       []string        `json:"board"`     // строки доски сверху вниз: "." - пусто, "B" и "W" - камни
+	Captures    map[string]int  `json:"captures"`  // пленные по цветам игроков
+	Connected   map[string]bool `json:"connected"` // подключены ли игроки, по цветам
+	Seq         int64           `json:"seq"`       // номер последнего события партии, с него можно запросить resync
 }
 
 // @name GetGameInfoRequest
@@ -86,18 +269,65 @@ type GetGameInfoRequest struct {
 
 // @name GetGameInfoResponse
 type GetGameInfoResponse struct {
-	Game                Game   `json:"game"`
-	PlayerBlackNickname string `json:"player_black_nickname" bson:"player_black_nickname"`
-	PlayerWhiteNickname string `json:"player_white_nickname" bson:"player_white_nickname"`
+	Game                Game           `json:"game"`
+	PlayerBlackNickname string         `json:"player_black_nickname" bson:"player_black_nickname"`
+	PlayerWhiteNickname string         `json:"player_white_nickname" bson:"player_white_nickname"`
+	PlayerBlackRating   float64        `json:"player_black_rating,omitempty" bson:"-"`
+	PlayerWhiteRating   float64        `json:"player_white_rating,omitempty" bson:"-"`
+	PlayerBlackRank     *rank.Rank     `json:"player_black_rank,omitempty" bson:"-"`
+	PlayerWhiteRank     *rank.Rank     `json:"player_white_rank,omitempty" bson:"-"`
+	Spectators          int            `json:"spectators" bson:"-"` // зрители, подключённые сейчас
+	Chat                []chat.Message `json:"chat" bson:"-"`       // канал зрителей виден только после завершения партии
+}
+
+// @name HandicapSuggestionRequest
+type HandicapSuggestionRequest struct {
+	OpponentID string `json:"opponent_id"`
+	BoardSize  int    `json:"board_size"`
+	Rules      string `json:"rules,omitempty"`
+}
+
+// @name HandicapSuggestion
+type HandicapSuggestion struct {
+	Handicap       int       `json:"handicap"` // 0 - ровная партия, 1 - чёрные ходят первыми без коми
+	Komi           float64   `json:"komi"`
+	IsCreatorBlack bool      `json:"is_creator_black"` // чёрными играет более слабый игрок
+	CreatorRank    rank.Rank `json:"creator_rank"`
+	OpponentRank   rank.Rank `json:"opponent_rank"`
 }
 
 // @name CreateGameRequest
 type CreateGameRequest struct {
-	BoardSize      int     `json:"board_size" bson:"board_size"`
-	Komi           float64 `json:"komi" bson:"komi"`
-	IsCreatorBlack bool    `json:"is_creator_black" bson:"is_creator_black"`
+	BoardSize      int               `json:"board_size" bson:"board_size"`
+	Komi           float64           `json:"komi" bson:"komi"`
+	IsCreatorBlack bool              `json:"is_creator_black" bson:"is_creator_black"`
+	Rules          string            `json:"rules,omitempty" bson:"rules,omitempty"` // chinese, japanese, korean, aga, new_zealand, tromp_taylor
+	Handicap       int               `json:"handicap,omitempty" bson:"handicap,omitempty"`
+	HandicapType   string            `json:"handicap_type,omitempty" bson:"handicap_type,omitempty"` // fixed (по умолчанию) или free
+	Rated          bool              `json:"rated,omitempty" bson:"rated,omitempty"`
+	AllowUndo      bool              `json:"allow_undo,omitempty" bson:"allow_undo,omitempty"` // в рейтинговых играх отмена запрещена
+	UndoLimit      int               `json:"undo_limit,omitempty" bson:"undo_limit,omitempty"` // 0 - без ограничения
+	TimeControl    clock.TimeControl `json:"time_control,omitempty" bson:"time_control,omitempty"`
+	IsPublic       bool              `json:"is_public,omitempty" bson:"is_public,omitempty"`           // к публичной партии могут подключаться зрители
+	MaxSpectators  int               `json:"max_spectators,omitempty" bson:"max_spectators,omitempty"` // 0 - без ограничения
 }
 
+// CreateBotGameRequest запрос на партию против KataGo.
+type CreateBotGameRequest struct {
+	BoardSize int     `json:"board_size,omitempty"` // до 19, по умолчанию 19
+	Komi      float64 `json:"komi,omitempty"`
+	Rules     string  `json:"rules,omitempty"`
+	Handicap  int     `json:"handicap,omitempty"`  // камни форы ставятся фиксированной расстановкой
+	BotColor  string  `json:"bot_color,omitempty"` // B или W (по умолчанию)
+	Level     string  `json:"level,omitempty"`     // уровень бота от 20k до 9d, по умолчанию 5k
+	IsPublic  bool    `json:"is_public,omitempty"`
+}
+
+const (
+	HandicapFixed = "fixed"
+	HandicapFree  = "free"
+)
+
 // @name ArchiveResponse
 type ArchiveResponse struct {
 	Games             []GameFromArchive `json:"games" bson:"games"`
@@ -1,9 +1,13 @@
 package game
 
+import "time"
+
 // @name Move
 type Move struct {
-	Color       string `json:"color"`
-	Coordinates string `json:"coordinates"`
+	Color       string     `json:"color" bson:"color"`
+	Coordinates string     `json:"coordinates" bson:"coordinates"`
+	MoveNumber  int        `json:"move_number,omitempty" bson:"move_number,omitempty"`
+	PlayedAt    *time.Time `json:"played_at,omitempty" bson:"played_at,omitempty"`
 }
 
 // @name MovePSV
@@ -31,3 +35,15 @@ type BotResponse struct {
 type Moves struct {
 	Moves []Move `json:"moves"`
 }
+
+// @name BotPosition
+// BotPosition позиция, в которой KataGo выбирает ход: настройки партии, камни
+// начальной расстановки и сыгранные ходы.
+type BotPosition struct {
+	BoardSize int     `json:"board_size,omitempty"` // по умолчанию 19
+	Komi      float64 `json:"komi,omitempty"`       // по умолчанию коми правил
+	Rules     string  `json:"rules,omitempty"`
+	Setup     []Move  `json:"setup,omitempty"` // камни форы и другой начальной расстановки
+	Moves     []Move  `json:"moves"`
+	ToMove    string  `json:"to_move,omitempty"` // B или W, по умолчанию по очерёдности ходов
+}
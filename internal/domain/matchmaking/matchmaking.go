@@ -0,0 +1,122 @@
+package matchmaking
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"team_exe/internal/domain/clock"
+)
+
+// Состояния игрока в подборе соперника.
+const (
+	StateIdle      = "idle"
+	StateSearching = "searching"
+	StateMatched   = "matched"
+)
+
+// @name MatchmakingJoinRequest
+type JoinRequest struct {
+	BoardSize   int               `json:"board_size"`
+	TimeControl clock.TimeControl `json:"time_control"`
+	Rated       bool              `json:"rated"`
+}
+
+// Request заявка игрока в очереди подбора соперника.
+type Request struct {
+	UserID      string            `json:"user_id"`
+	Rating      float64           `json:"rating"`
+	BoardSize   int               `json:"board_size"`
+	TimeControl clock.TimeControl `json:"time_control"`
+	Rated       bool              `json:"rated"`
+	JoinedAt    time.Time         `json:"joined_at"`
+}
+
+// Window допустимая разница рейтингов соперников. Она начинается с Base и растёт
+// на PerMinute за каждую минуту ожидания, но не выше Max.
+type Window struct {
+	Base      float64
+	PerMinute float64
+	Max       float64
+}
+
+// DefaultWindow окно подбора, если в конфигурации оно не задано.
+var DefaultWindow = Window{Base: 100, PerMinute: 50, Max: 600}
+
+// WithDefaults подставляет значения по умолчанию вместо незаданных параметров окна.
+func (w Window) WithDefaults() Window {
+	if w.Base <= 0 {
+		w.Base = DefaultWindow.Base
+	}
+	if w.PerMinute <= 0 {
+		w.PerMinute = DefaultWindow.PerMinute
+	}
+	if w.Max <= 0 {
+		w.Max = DefaultWindow.Max
+	}
+	return w
+}
+
+// At возвращает ширину окна после ожидания waited.
+func (w Window) At(waited time.Duration) float64 {
+	if waited < 0 {
+		waited = 0
+	}
+	return math.Min(w.Base+w.PerMinute*waited.Minutes(), w.Max)
+}
+
+// Compatible проверяет, что заявки можно свести в одну партию: настройки партии совпадают,
+// а разница рейтингов укладывается в окно каждого из игроков.
+func Compatible(a, b Request, w Window, now time.Time) bool {
+	if a.UserID == b.UserID || a.BoardSize != b.BoardSize || a.Rated != b.Rated || a.TimeControl != b.TimeControl {
+		return false
+	}
+	diff := math.Abs(a.Rating - b.Rating)
+	return diff <= w.At(now.Sub(a.JoinedAt)) && diff <= w.At(now.Sub(b.JoinedAt))
+}
+
+// Pair сводит заявки в пары. Первыми подбираются соперники для тех, кто ждёт дольше,
+// из подходящих выбирается ближайший по рейтингу.
+func Pair(requests []Request, w Window, now time.Time) [][2]Request {
+	queue := make([]Request, len(requests))
+	copy(queue, requests)
+	sort.Slice(queue, func(i, j int) bool { return queue[i].JoinedAt.Before(queue[j].JoinedAt) })
+
+	paired := make([]bool, len(queue))
+	var pairs [][2]Request
+	for i := range queue {
+		if paired[i] {
+			continue
+		}
+		best := -1
+		for j := i + 1; j < len(queue); j++ {
+			if paired[j] || !Compatible(queue[i], queue[j], w, now) {
+				continue
+			}
+			if best == -1 || math.Abs(queue[i].Rating-queue[j].Rating) < math.Abs(queue[i].Rating-queue[best].Rating) {
+				best = j
+			}
+		}
+		if best == -1 {
+			continue
+		}
+		paired[i], paired[best] = true, true
+		pairs = append(pairs, [2]Request{queue[i], queue[best]})
+	}
+	return pairs
+}
+
+// Match созданная для игрока партия.
+type Match struct {
+	GameKeyPublic string `json:"public_key"`
+	Color         string `json:"color"` // B или W
+	OpponentID    string `json:"opponent_id"`
+}
+
+// @name MatchmakingStatus
+type Status struct {
+	State         string  `json:"state"` // idle, searching или matched
+	WaitedSeconds int     `json:"waited_seconds,omitempty"`
+	RatingWindow  float64 `json:"rating_window,omitempty"`
+	Match         *Match  `json:"match,omitempty"`
+}
@@ -0,0 +1,138 @@
+package matchmaking
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"team_exe/internal/domain/clock"
+)
+
+var now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// request заявка на партию 19x19 с абсолютным контролем, поданная waited назад.
+func request(userID string, rating float64, waited time.Duration) Request {
+	return Request{
+		UserID:      userID,
+		Rating:      rating,
+		BoardSize:   19,
+		TimeControl: clock.TimeControl{Type: clock.TypeAbsolute, MainTime: 600},
+		JoinedAt:    now.Add(-waited),
+	}
+}
+
+func TestWindowAt(t *testing.T) {
+	w := Window{Base: 100, PerMinute: 50, Max: 600}
+	tests := []struct {
+		waited time.Duration
+		want   float64
+	}{
+		{waited: 0, want: 100},
+		{waited: -time.Minute, want: 100},
+		{waited: 30 * time.Second, want: 125},
+		{waited: 2 * time.Minute, want: 200},
+		{waited: 20 * time.Minute, want: 600},
+	}
+
+	for _, tt := range tests {
+		if got := w.At(tt.waited); got != tt.want {
+			t.Errorf("At(%v) = %v, want %v", tt.waited, got, tt.want)
+		}
+	}
+}
+
+func TestWindowWithDefaults(t *testing.T) {
+	if got := (Window{}).WithDefaults(); got != DefaultWindow {
+		t.Errorf("WithDefaults = %+v, want %+v", got, DefaultWindow)
+	}
+	w := Window{Base: 10, PerMinute: 5, Max: 20}
+	if got := w.WithDefaults(); got != w {
+		t.Errorf("WithDefaults = %+v, want %+v", got, w)
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	w := DefaultWindow
+	other19 := request("b", 1500, 0)
+	other19.BoardSize = 13
+	rated := request("b", 1500, 0)
+	rated.Rated = true
+	otherClock := request("b", 1500, 0)
+	otherClock.TimeControl.MainTime = 300
+
+	tests := []struct {
+		name string
+		a, b Request
+		want bool
+	}{
+		{name: "same settings and rating", a: request("a", 1500, 0), b: request("b", 1500, 0), want: true},
+		{name: "same user", a: request("a", 1500, 0), b: request("a", 1500, 0), want: false},
+		{name: "different board size", a: request("a", 1500, 0), b: other19, want: false},
+		{name: "rated and unrated", a: request("a", 1500, 0), b: rated, want: false},
+		{name: "different time control", a: request("a", 1500, 0), b: otherClock, want: false},
+		{name: "rating gap outside of the window", a: request("a", 1500, 0), b: request("b", 1650, 0), want: false},
+		{name: "window widens for both", a: request("a", 1500, time.Minute), b: request("b", 1650, time.Minute), want: true},
+		{name: "window must fit both players", a: request("a", 1500, 10*time.Minute), b: request("b", 1650, 0), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compatible(tt.a, tt.b, w, now); got != tt.want {
+				t.Errorf("Compatible = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPair(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests []Request
+		want     [][2]string
+	}{
+		{
+			name:     "nobody to pair",
+			requests: []Request{request("a", 1500, 0)},
+		},
+		{
+			name: "longest waiting player gets the closest rating",
+			requests: []Request{
+				request("c", 1550, time.Minute),
+				request("a", 1500, 5*time.Minute),
+				request("d", 1520, 0),
+				request("b", 1900, 4*time.Minute),
+			},
+			want: [][2]string{{"a", "d"}},
+		},
+		{
+			name: "everybody is paired",
+			requests: []Request{
+				request("a", 1500, 3*time.Minute),
+				request("b", 1600, 2*time.Minute),
+				request("c", 1510, time.Minute),
+				request("d", 1580, 0),
+			},
+			want: [][2]string{{"a", "c"}, {"b", "d"}},
+		},
+		{
+			name: "gap closes as players wait",
+			requests: []Request{
+				request("a", 1500, 8*time.Minute),
+				request("b", 1800, 6*time.Minute),
+			},
+			want: [][2]string{{"a", "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][2]string
+			for _, pair := range Pair(tt.requests, DefaultWindow, now) {
+				got = append(got, [2]string{pair[0].UserID, pair[1].UserID})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Pair = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
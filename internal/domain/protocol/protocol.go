@@ -0,0 +1,263 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/bot"
+	"team_exe/internal/domain/chat"
+	"team_exe/internal/domain/coord"
+	errs "team_exe/internal/errors"
+)
+
+var (
+	ErrUpgradeRequired    = errors.New("client protocol version is outdated, upgrade required")
+	ErrUnsupportedVersion = errors.New("client protocol version is newer than the server supports")
+	ErrBadMessage         = errors.New("message is not a valid envelope")
+	ErrUnknownType        = errors.New("unknown message type")
+)
+
+// Version текущая версия протокола сокета игры. Клиенты с версией ниже MinVersion
+// или без версии получают ошибку upgrade_required.
+const (
+	Version    = 1
+	MinVersion = 1
+)
+
+// Типы сообщений. Клиент присылает действия move, pass, resign, chat, toggle_dead,
+// accept_score, undo_* и запросы clock и resync; сервер отвечает ack или error и рассылает события.
+const (
+	TypeMove        = "move"
+	TypePass        = "pass"
+	TypeResign      = "resign"
+	TypeChat        = "chat"
+	TypeToggleDead  = "toggle_dead"
+	TypeAcceptScore = "accept_score"
+	TypeUndoRequest = "undo_request"
+	TypeUndoAccept  = "undo_accept"
+	TypeUndoDecline = "undo_decline"
+	TypeClock       = "clock"
+	TypeResync      = "resync"
+	TypeError       = "error"
+	TypeGameState   = "game-state"
+	TypeAck         = "ack"
+	TypeNotice      = "notice"
+)
+
+// Коды ошибок самого протокола.
+const (
+	CodeUpgradeRequired    = "upgrade_required"
+	CodeUnsupportedVersion = "unsupported_version"
+	CodeBadMessage         = "bad_message"
+	CodeUnknownType        = "unknown_type"
+	CodeInternal           = "internal" // ошибка, которой нет в таблице кодов
+)
+
+// Коды ошибок игровой логики.
+const (
+	CodeOutOfBoard      = "out_of_board"
+	CodeOccupied        = "occupied"
+	CodeSuicide         = "suicide"
+	CodeKo              = "ko"
+	CodeWrongTurn       = "wrong_turn"
+	CodeBadMove         = "bad_move"
+	CodeNoStone         = "no_stone"
+	CodeWrongColor      = "wrong_color"
+	CodeGameNotStarted  = "game_not_started"
+	CodeNotAPlayer      = "not_a_player"
+	CodeGameNotInPlay   = "game_not_in_play"
+	CodeUnknownAction   = "unknown_action"
+	CodeNotScoringPhase = "not_scoring_phase"
+	CodeHandicapPending = "handicap_pending"
+	CodeUndoDisabled    = "undo_disabled"
+	CodeUndoLimit       = "undo_limit"
+	CodeNothingToUndo   = "nothing_to_undo"
+	CodeNoUndoRequest   = "no_undo_request"
+	CodeGameNotPublic   = "game_not_public"
+	CodeSpectatorLimit  = "spectator_limit"
+	CodeChatEmpty       = "chat_empty"
+	CodeChatTooLong     = "chat_too_long"
+	CodeUnknownChannel  = "unknown_channel"
+	CodeChatRateLimited = "chat_rate_limited"
+	CodeKibitzClosed    = "kibitz_closed"
+	CodeBadBotColor     = "bad_bot_color"
+	CodeBotBoardSize    = "bot_board_size"
+	CodeUnknownBotLevel = "unknown_bot_level"
+)
+
+// errorCodes коды, которые получает клиент для каждой известной ошибки.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrUpgradeRequired, CodeUpgradeRequired},
+	{ErrUnsupportedVersion, CodeUnsupportedVersion},
+	{ErrBadMessage, CodeBadMessage},
+	{ErrUnknownType, CodeUnknownType},
+
+	{board.ErrOutOfBoard, CodeOutOfBoard},
+	{coord.ErrOutOfRange, CodeOutOfBoard},
+	{board.ErrOccupied, CodeOccupied},
+	{board.ErrSuicide, CodeSuicide},
+	{board.ErrKo, CodeKo},
+	{board.ErrWrongTurn, CodeWrongTurn},
+	{coord.ErrBadCoordinate, CodeBadMove},
+	{board.ErrUnknownColor, CodeBadMove},
+	{board.ErrNoStone, CodeNoStone},
+	{errs.ErrNotYourColor, CodeWrongColor},
+	{errs.ErrGameNotStarted, CodeGameNotStarted},
+	{errs.ErrNotAPlayer, CodeNotAPlayer},
+	{errs.ErrGameNotInPlay, CodeGameNotInPlay},
+	{errs.ErrUnknownAction, CodeUnknownAction},
+	{errs.ErrNotScoringPhase, CodeNotScoringPhase},
+	{errs.ErrHandicapPending, CodeHandicapPending},
+	{errs.ErrUndoDisabled, CodeUndoDisabled},
+	{errs.ErrUndoLimit, CodeUndoLimit},
+	{errs.ErrNothingToUndo, CodeNothingToUndo},
+	{errs.ErrNoUndoRequest, CodeNoUndoRequest},
+	{errs.ErrGameNotPublic, CodeGameNotPublic},
+	{errs.ErrSpectatorLimit, CodeSpectatorLimit},
+	{chat.ErrEmpty, CodeChatEmpty},
+	{chat.ErrTooLong, CodeChatTooLong},
+	{chat.ErrUnknownChannel, CodeUnknownChannel},
+	{chat.ErrRateLimited, CodeChatRateLimited},
+	{errs.ErrKibitzForPlayers, CodeKibitzClosed},
+	{errs.ErrBadBotColor, CodeBadBotColor},
+	{errs.ErrBotBoardSize, CodeBotBoardSize},
+	{bot.ErrUnknownLevel, CodeUnknownBotLevel},
+}
+
+// Коды уведомлений.
+const (
+	NoticeOpponentOffline = "opponent_offline"
+	NoticeReplaced        = "replaced_by_new_connection"
+	NoticeDisconnected    = "player_disconnected"
+	NoticeReconnected     = "player_reconnected"
+	NoticeBotUnavailable  = "bot_unavailable"
+)
+
+// @name Envelope
+// Envelope сообщение сокета игры. Seq у событий партии растёт на единицу с каждым событием,
+// у сообщений клиента это его собственный номер, на который ссылаются ack и error.
+type Envelope struct {
+	Type    string          `json:"type"`
+	Seq     int64           `json:"seq"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// @name ResyncPayload
+// Resync запрос пропущенных событий. Без SinceSeq, или если события уже вытеснены из журнала,
+// сервер присылает полное состояние партии.
+type Resync struct {
+	SinceSeq *int64 `json:"since_seq,omitempty"`
+}
+
+// @name AckPayload
+type Ack struct {
+	ReplyTo int64 `json:"reply_to"`
+}
+
+// @name ErrorPayload
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	ReplyTo int64  `json:"reply_to,omitempty"`
+}
+
+// @name NoticePayload
+type Notice struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Color   string `json:"color,omitempty"` // цвет игрока, к которому относится уведомление
+}
+
+// New собирает сообщение с закодированной нагрузкой.
+func New(typ string, seq int64, payload any) (Envelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{Type: typ, Seq: seq, Payload: data}, nil
+}
+
+// Decode разбирает сообщение клиента.
+func Decode(data []byte) (Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Type == "" {
+		return Envelope{}, ErrBadMessage
+	}
+	return env, nil
+}
+
+// Negotiate проверяет версию протокола, которую клиент передал при подключении.
+func Negotiate(version string) (int, error) {
+	v, err := strconv.Atoi(version)
+	if err != nil || v < MinVersion {
+		return 0, ErrUpgradeRequired
+	}
+	if v > Version {
+		return 0, ErrUnsupportedVersion
+	}
+	return v, nil
+}
+
+// ErrorCode возвращает машиночитаемый код ошибки для клиента, для ошибок не из таблицы - CodeInternal.
+func ErrorCode(err error) string {
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	return CodeInternal
+}
+
+// FeedSize сколько последних событий партии хранится для повторной отправки.
+const FeedSize = 256
+
+// Feed нумерует события одной партии и хранит последние из них.
+type Feed struct {
+	seq int64
+	log []Envelope
+}
+
+// Next присваивает событию следующий номер и запоминает его.
+func (f *Feed) Next(typ string, payload any) (Envelope, error) {
+	env, err := New(typ, f.seq+1, payload)
+	if err != nil {
+		return Envelope{}, err
+	}
+	f.seq++
+	if len(f.log) == FeedSize {
+		f.log = append(f.log[:0], f.log[1:]...)
+	}
+	f.log = append(f.log, env)
+	return env, nil
+}
+
+// Restart очищает журнал и продолжает нумерацию событий после seq.
+func (f *Feed) Restart(seq int64) {
+	f.seq = seq
+	f.log = nil
+}
+
+// Seq возвращает номер последнего события.
+func (f *Feed) Seq() int64 {
+	return f.seq
+}
+
+// Since возвращает события с номерами больше seq. Возвращает false, если часть этих событий
+// уже вытеснена из журнала или seq из будущего.
+func (f *Feed) Since(seq int64) ([]Envelope, bool) {
+	if seq > f.seq || seq < 0 {
+		return nil, false
+	}
+	if seq == f.seq {
+		return nil, true
+	}
+	if len(f.log) == 0 || f.log[0].Seq > seq+1 {
+		return nil, false
+	}
+	events := f.log[seq+1-f.log[0].Seq:]
+	return append([]Envelope(nil), events...), true
+}
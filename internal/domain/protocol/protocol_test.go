@@ -0,0 +1,33 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/coord"
+	errs "team_exe/internal/errors"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"protocol error", ErrBadMessage, CodeBadMessage},
+		{"board error", board.ErrKo, CodeKo},
+		{"several errors share a code", coord.ErrOutOfRange, CodeOutOfBoard},
+		{"wrapped error", fmt.Errorf("ход 3: %w", board.ErrSuicide), CodeSuicide},
+		{"game logic error", errs.ErrHandicapPending, CodeHandicapPending},
+		{"unknown error", errors.New("boom"), CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCode(tt.err); got != tt.want {
+				t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
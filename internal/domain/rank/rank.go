@@ -0,0 +1,131 @@
+package rank
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrBadRank = errors.New("invalid rank")
+
+// Виды рангов.
+const (
+	Kyu = "kyu"
+	Dan = "dan"
+	Pro = "pro"
+)
+
+// Пределы любительских рангов.
+const (
+	MaxKyu = 30
+	MaxDan = 9
+	MaxPro = 9
+)
+
+// Rank ранг игрока: 15k, 1d или 3p.
+type Rank struct {
+	Kind  string `json:"kind"`
+	Level int    `json:"level"`
+}
+
+// Parse разбирает ранг из записей вида "15k", "15 kyu", "1d", "3 dan", "9p".
+// Знаки неуверенности вроде "?" или "*", как в архивных SGF, отбрасываются.
+func Parse(s string) (Rank, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimRight(s, "?* ")
+	digits := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) })
+	if digits <= 0 {
+		return Rank{}, ErrBadRank
+	}
+	level, err := strconv.Atoi(s[:digits])
+	if err != nil {
+		return Rank{}, ErrBadRank
+	}
+
+	var r Rank
+	switch strings.TrimSpace(s[digits:]) {
+	case "k", "kyu":
+		r = Rank{Kind: Kyu, Level: level}
+	case "d", "dan":
+		r = Rank{Kind: Dan, Level: level}
+	case "p", "pro":
+		r = Rank{Kind: Pro, Level: level}
+	default:
+		return Rank{}, ErrBadRank
+	}
+	if !r.Valid() {
+		return Rank{}, ErrBadRank
+	}
+	return r, nil
+}
+
+// Valid проверяет, что уровень ранга в допустимых пределах.
+func (r Rank) Valid() bool {
+	switch r.Kind {
+	case Kyu:
+		return r.Level >= 1 && r.Level <= MaxKyu
+	case Dan:
+		return r.Level >= 1 && r.Level <= MaxDan
+	case Pro:
+		return r.Level >= 1 && r.Level <= MaxPro
+	}
+	return false
+}
+
+// String возвращает ранг в краткой записи: "15k", "1d", "3p".
+func (r Rank) String() string {
+	switch r.Kind {
+	case Kyu:
+		return strconv.Itoa(r.Level) + "k"
+	case Dan:
+		return strconv.Itoa(r.Level) + "d"
+	case Pro:
+		return strconv.Itoa(r.Level) + "p"
+	}
+	return ""
+}
+
+// Value переводит ранг на сплошную шкалу, где разница в единицу - один камень форы:
+// 1k = 0, 1d = 1, 9d = 9. Профессиональные ранги идут выше любительских с шагом в треть камня.
+func (r Rank) Value() float64 {
+	switch r.Kind {
+	case Kyu:
+		return float64(1 - r.Level)
+	case Dan:
+		return float64(r.Level)
+	case Pro:
+		return MaxDan + float64(r.Level)/3
+	}
+	return math.Inf(-1)
+}
+
+// Compare возвращает -1, 0 или 1, если ранг r слабее, равен или сильнее o.
+func (r Rank) Compare(o Rank) int {
+	switch a, b := r.Value(), o.Value(); {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// MarshalJSON добавляет к полям ранга его краткую запись.
+func (r Rank) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind  string `json:"kind"`
+		Level int    `json:"level"`
+		Name  string `json:"name"`
+	}{r.Kind, r.Level, r.String()})
+}
+
+// fromValue возвращает любительский ранг для значения шкалы Value.
+func fromValue(v int) Rank {
+	if v >= 1 {
+		return Rank{Kind: Dan, Level: min(v, MaxDan)}
+	}
+	return Rank{Kind: Kyu, Level: min(1-v, MaxKyu)}
+}
@@ -0,0 +1,129 @@
+package rank
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Rank
+		wantErr error
+	}{
+		{s: "15k", want: Rank{Kind: Kyu, Level: 15}},
+		{s: "15 kyu", want: Rank{Kind: Kyu, Level: 15}},
+		{s: "1d", want: Rank{Kind: Dan, Level: 1}},
+		{s: " 3 Dan ", want: Rank{Kind: Dan, Level: 3}},
+		{s: "9p", want: Rank{Kind: Pro, Level: 9}},
+		{s: "2k?", want: Rank{Kind: Kyu, Level: 2}},
+		{s: "4d*", want: Rank{Kind: Dan, Level: 4}},
+		{s: "30k", want: Rank{Kind: Kyu, Level: 30}},
+		{s: "31k", wantErr: ErrBadRank},
+		{s: "10d", wantErr: ErrBadRank},
+		{s: "0k", wantErr: ErrBadRank},
+		{s: "k", wantErr: ErrBadRank},
+		{s: "5x", wantErr: ErrBadRank},
+		{s: "", wantErr: ErrBadRank},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := Parse(tt.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.s, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("Parse(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	order := []string{"30k", "10k", "1k", "1d", "9d", "1p", "9p"}
+	for i := 1; i < len(order); i++ {
+		weaker, stronger := mustParse(t, order[i-1]), mustParse(t, order[i])
+		if weaker.Compare(stronger) != -1 || stronger.Compare(weaker) != 1 {
+			t.Errorf("%s should be weaker than %s", weaker, stronger)
+		}
+	}
+	if r := mustParse(t, "3k"); r.Compare(r) != 0 {
+		t.Errorf("%s should be equal to itself", r)
+	}
+}
+
+func TestHandicap(t *testing.T) {
+	tests := []struct {
+		name     string
+		stronger string
+		weaker   string
+		size     int
+		want     int
+	}{
+		{name: "one stone per rank on 19x19", stronger: "1d", weaker: "5k", size: 19, want: 5},
+		{name: "across the kyu-dan border", stronger: "1d", weaker: "1k", size: 19, want: 1},
+		{name: "equal ranks", stronger: "3k", weaker: "3k", size: 19, want: 0},
+		{name: "two ranks per stone on 13x13", stronger: "2k", weaker: "8k", size: 13, want: 3},
+		{name: "rounding on 13x13", stronger: "1d", weaker: "5k", size: 13, want: 3},
+		{name: "three ranks per stone on 9x9", stronger: "1d", weaker: "5k", size: 9, want: 2},
+		{name: "small difference on 9x9", stronger: "4k", weaker: "5k", size: 9, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Handicap(mustParse(t, tt.stronger), mustParse(t, tt.weaker), tt.size)
+			if got != tt.want {
+				t.Errorf("Handicap(%s, %s, %d) = %d, want %d", tt.stronger, tt.weaker, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaleFromRating(t *testing.T) {
+	tests := []struct {
+		rating float64
+		want   string
+	}{
+		{rating: 1500, want: "6k"},
+		{rating: 2099, want: "1k"},
+		{rating: 2100, want: "1d"},
+		{rating: 2250, want: "2d"},
+		{rating: 5000, want: "9d"},
+		{rating: -1000, want: "30k"},
+	}
+
+	for _, tt := range tests {
+		if got := DefaultScale.FromRating(tt.rating); got.String() != tt.want {
+			t.Errorf("FromRating(%v) = %s, want %s", tt.rating, got, tt.want)
+		}
+	}
+}
+
+func TestScaleRoundTrip(t *testing.T) {
+	scales := []Scale{DefaultScale, {DanRating: 2000, Step: 60}, {}}
+	for _, scale := range scales {
+		for level := 1; level <= MaxKyu; level++ {
+			checkRoundTrip(t, scale, Rank{Kind: Kyu, Level: level})
+		}
+		for level := 1; level <= MaxDan; level++ {
+			checkRoundTrip(t, scale, Rank{Kind: Dan, Level: level})
+		}
+	}
+}
+
+func checkRoundTrip(t *testing.T, scale Scale, r Rank) {
+	t.Helper()
+	if got := scale.FromRating(scale.ToRating(r)); got != r {
+		t.Errorf("scale %+v: FromRating(ToRating(%s)) = %s", scale, r, got)
+	}
+}
+
+func mustParse(t *testing.T, s string) Rank {
+	t.Helper()
+	r, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return r
+}
@@ -0,0 +1,50 @@
+package rank
+
+import "math"
+
+// Scale границы рангов на шкале рейтинга: ранг 1d начинается с рейтинга DanRating,
+// а каждый следующий ранг вверх или вниз отстоит на Step пунктов.
+type Scale struct {
+	DanRating float64
+	Step      float64
+}
+
+// DefaultScale границы, по которым ранг выводится из рейтинга. Начальный рейтинг 1500
+// соответствует 6k. При запуске границы переопределяются из конфигурации.
+var DefaultScale = Scale{DanRating: 2100, Step: 100}
+
+// WithDefaults подставляет значения по умолчанию вместо незаданных границ.
+func (s Scale) WithDefaults() Scale {
+	if s.DanRating == 0 {
+		s.DanRating = DefaultScale.DanRating
+	}
+	if s.Step <= 0 {
+		s.Step = DefaultScale.Step
+	}
+	return s
+}
+
+// FromRating возвращает любительский ранг, в границы которого попадает рейтинг.
+func (s Scale) FromRating(rating float64) Rank {
+	s = s.WithDefaults()
+	return fromValue(int(math.Floor((rating-s.DanRating)/s.Step)) + 1)
+}
+
+// ToRating возвращает рейтинг середины границ ранга.
+func (s Scale) ToRating(r Rank) float64 {
+	s = s.WithDefaults()
+	return s.DanRating + (r.Value()-1)*s.Step + s.Step/2
+}
+
+// Handicap число камней форы, которое разница рангов даёт на доске size x size.
+// На малых досках один камень стоит нескольких рангов: 2 на 13x13 и 3 на 9x9.
+func Handicap(stronger, weaker Rank, size int) int {
+	ranksPerStone := 1.0
+	switch {
+	case size <= 9:
+		ranksPerStone = 3
+	case size <= 13:
+		ranksPerStone = 2
+	}
+	return int(math.Round((stronger.Value() - weaker.Value()) / ranksPerStone))
+}
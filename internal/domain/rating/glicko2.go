@@ -0,0 +1,154 @@
+package rating
+
+import (
+	"math"
+	"time"
+)
+
+// Начальные значения для нового игрока и параметры системы Glicko-2.
+const (
+	DefaultRating     = 1500.0
+	DefaultDeviation  = 350.0
+	DefaultVolatility = 0.06
+
+	// tau ограничивает изменение волатильности между периодами.
+	tau = 0.5
+	// scale переводит рейтинг из шкалы Glicko в шкалу Glicko-2.
+	scale   = 173.7178
+	epsilon = 0.000001
+)
+
+// Итог партии для расчёта рейтинга.
+const (
+	ScoreWin  = 1.0
+	ScoreDraw = 0.5
+	ScoreLoss = 0.0
+)
+
+// Rating рейтинг игрока по Glicko-2 в привычной шкале (1500 ± 350).
+type Rating struct {
+	Rating     float64 `json:"rating" bson:"rating"`
+	Deviation  float64 `json:"deviation" bson:"deviation"`
+	Volatility float64 `json:"volatility" bson:"volatility"`
+}
+
+// Default возвращает рейтинг игрока, ещё не сыгравшего рейтинговых партий.
+func Default() Rating {
+	return Rating{Rating: DefaultRating, Deviation: DefaultDeviation, Volatility: DefaultVolatility}
+}
+
+// OrDefault заменяет незаполненный рейтинг начальным.
+func (r Rating) OrDefault() Rating {
+	if r.Deviation <= 0 || r.Volatility <= 0 {
+		return Default()
+	}
+	return r
+}
+
+// Result партия рейтингового периода против соперника с рейтингом Opponent.
+type Result struct {
+	Opponent Rating
+	Score    float64 // ScoreWin, ScoreDraw или ScoreLoss
+}
+
+// Update возвращает рейтинг игрока r после периода с партиями results.
+// Без партий растёт только отклонение рейтинга.
+func Update(r Rating, results []Result) Rating {
+	r = r.OrDefault()
+	mu := (r.Rating - DefaultRating) / scale
+	phi := r.Deviation / scale
+	sigma := r.Volatility
+
+	if len(results) == 0 {
+		return fromScale(mu, math.Sqrt(phi*phi+sigma*sigma), sigma)
+	}
+
+	var variance, improvement float64
+	for _, result := range results {
+		opponent := result.Opponent.OrDefault()
+		muJ := (opponent.Rating - DefaultRating) / scale
+		phiJ := opponent.Deviation / scale
+		g := gPhi(phiJ)
+		e := expected(mu, muJ, g)
+		variance += g * g * e * (1 - e)
+		improvement += g * (result.Score - e)
+	}
+	v := 1 / variance
+	delta := v * improvement
+
+	sigma = newVolatility(phi, sigma, v, delta)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * improvement
+	return fromScale(mu, phi, sigma)
+}
+
+func fromScale(mu, phi, sigma float64) Rating {
+	deviation := math.Min(phi*scale, DefaultDeviation)
+	return Rating{
+		Rating:     mu*scale + DefaultRating,
+		Deviation:  deviation,
+		Volatility: sigma,
+	}
+}
+
+func gPhi(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muJ, g float64) float64 {
+	return 1 / (1 + math.Exp(-g*(mu-muJ)))
+}
+
+// newVolatility находит новую волатильность итерационным методом Иллинойса.
+func newVolatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
+
+// HistoryEntry изменение рейтинга игрока после рейтинговой партии.
+type HistoryEntry struct {
+	UserID     string    `json:"user_id" bson:"user_id"`
+	GameKey    string    `json:"game_key" bson:"game_key"`
+	OpponentID string    `json:"opponent_id" bson:"opponent_id"`
+	Score      float64   `json:"score" bson:"score"`
+	Before     Rating    `json:"before" bson:"before"`
+	After      Rating    `json:"after" bson:"after"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+}
+
+// @name RatingHistoryResponse
+type HistoryResponse struct {
+	UserID  string         `json:"user_id"`
+	Current Rating         `json:"current"`
+	History []HistoryEntry `json:"history"`
+}
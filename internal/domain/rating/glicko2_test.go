@@ -0,0 +1,70 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		player  Rating
+		results []Result
+		want    Rating
+	}{
+		{
+			// пример из статьи Glickman "Example of the Glicko-2 system"
+			name:   "paper example",
+			player: Rating{Rating: 1500, Deviation: 200, Volatility: 0.06},
+			results: []Result{
+				{Opponent: Rating{Rating: 1400, Deviation: 30, Volatility: 0.06}, Score: ScoreWin},
+				{Opponent: Rating{Rating: 1550, Deviation: 100, Volatility: 0.06}, Score: ScoreLoss},
+				{Opponent: Rating{Rating: 1700, Deviation: 300, Volatility: 0.06}, Score: ScoreLoss},
+			},
+			want: Rating{Rating: 1464.06, Deviation: 151.52, Volatility: 0.05999},
+		},
+		{
+			name:   "no games only grows deviation",
+			player: Rating{Rating: 1500, Deviation: 200, Volatility: 0.06},
+			want:   Rating{Rating: 1500, Deviation: 200.27, Volatility: 0.06},
+		},
+		{
+			name:   "deviation is capped for inactive players",
+			player: Rating{Rating: 1800, Deviation: 349.99, Volatility: 0.06},
+			want:   Rating{Rating: 1800, Deviation: DefaultDeviation, Volatility: 0.06},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Update(tt.player, tt.results)
+			if math.Abs(got.Rating-tt.want.Rating) > 0.01 {
+				t.Errorf("Rating = %.4f, want %.2f", got.Rating, tt.want.Rating)
+			}
+			if math.Abs(got.Deviation-tt.want.Deviation) > 0.01 {
+				t.Errorf("Deviation = %.4f, want %.2f", got.Deviation, tt.want.Deviation)
+			}
+			if math.Abs(got.Volatility-tt.want.Volatility) > 0.00001 {
+				t.Errorf("Volatility = %.6f, want %.5f", got.Volatility, tt.want.Volatility)
+			}
+		})
+	}
+}
+
+func TestUpdateDefaultsEmptyRating(t *testing.T) {
+	win := []Result{{Opponent: Rating{}, Score: ScoreWin}}
+	if got, want := Update(Rating{}, win), Update(Default(), win); got != want {
+		t.Errorf("Update(empty) = %+v, want %+v", got, want)
+	}
+}
+
+func TestUpdateDrawBetweenEqualPlayers(t *testing.T) {
+	player := Rating{Rating: 1600, Deviation: 80, Volatility: 0.06}
+	got := Update(player, []Result{{Opponent: player, Score: ScoreDraw}})
+	if math.Abs(got.Rating-player.Rating) > 1e-9 {
+		t.Errorf("Rating = %v, want %v", got.Rating, player.Rating)
+	}
+	if got.Deviation >= player.Deviation {
+		t.Errorf("Deviation = %v, want less than %v", got.Deviation, player.Deviation)
+	}
+}
@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"errors"
+	"strings"
+
+	"team_exe/internal/domain/board"
+)
+
+var ErrUnknownRuleset = errors.New("unknown ruleset")
+
+// Ruleset набор правил, по которому ведётся партия.
+type Ruleset struct {
+	Name    string        // идентификатор в API
+	SgfName string        // значение свойства RU в SGF
+	Ko      board.KoRule  // правило повторения позиций
+	Scoring board.Scoring // способ подсчёта очков
+	Komi    float64       // коми в партии без форы
+}
+
+var (
+	Chinese     = Ruleset{Name: "chinese", SgfName: "Chinese", Ko: board.KoPositionalSuperko, Scoring: board.ScoringArea, Komi: 7.5}
+	Japanese    = Ruleset{Name: "japanese", SgfName: "Japanese", Ko: board.KoSimple, Scoring: board.ScoringTerritory, Komi: 6.5}
+	Korean      = Ruleset{Name: "korean", SgfName: "Korean", Ko: board.KoSimple, Scoring: board.ScoringTerritory, Komi: 6.5}
+	AGA         = Ruleset{Name: "aga", SgfName: "AGA", Ko: board.KoSituationalSuperko, Scoring: board.ScoringArea, Komi: 7.5}
+	NewZealand  = Ruleset{Name: "new_zealand", SgfName: "NZ", Ko: board.KoSituationalSuperko, Scoring: board.ScoringArea, Komi: 7}
+	TrompTaylor = Ruleset{Name: "tromp_taylor", SgfName: "Tromp-Taylor", Ko: board.KoPositionalSuperko, Scoring: board.ScoringArea, Komi: 7.5}
+)
+
+// Default правила, применяемые, если при создании игры они не указаны.
+var Default = Chinese
+
+var all = []Ruleset{Chinese, Japanese, Korean, AGA, NewZealand, TrompTaylor}
+
+// Parse находит набор правил по имени из API или по значению RU из SGF.
+// Пустое имя означает правила по умолчанию.
+func Parse(name string) (Ruleset, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Default, nil
+	}
+	for _, r := range all {
+		if strings.EqualFold(name, r.Name) || strings.EqualFold(name, r.SgfName) {
+			return r, nil
+		}
+	}
+	return Ruleset{}, ErrUnknownRuleset
+}
+
+// DefaultKomi возвращает коми по умолчанию: в партиях с форой белым остаётся только половина очка.
+func (r Ruleset) DefaultKomi(handicap int) float64 {
+	if handicap > 0 {
+		return 0.5
+	}
+	return r.Komi
+}
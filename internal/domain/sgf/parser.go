@@ -0,0 +1,272 @@
+package sgf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrEmptyCollection = errors.New("sgf: collection contains no game trees")
+
+// ParseError ошибка разбора SGF с указанием места в исходном тексте.
+type ParseError struct {
+	Offset int // смещение в байтах от начала текста
+	Line   int // номер строки, с единицы
+	Column int // номер символа в строке, с единицы
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("sgf: %d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// pointListProperties свойства со списком пунктов, в которых допускается сжатая запись "aa:cc".
+var pointListProperties = map[string]bool{
+	"AB": true, "AW": true, "AE": true, "TR": true, "SQ": true, "CR": true,
+	"MA": true, "SL": true, "DD": true, "VW": true, "TB": true, "TW": true,
+}
+
+// Parse разбирает SGF и возвращает первую партию коллекции.
+func Parse(text string) (*SGF, error) {
+	games, err := ParseCollection(text)
+	if err != nil {
+		return nil, err
+	}
+	return games[0], nil
+}
+
+// ParseCollection разбирает коллекцию SGF, в которой может быть несколько партий.
+// Экранирование в значениях снимается, сжатые списки пунктов разворачиваются.
+func ParseCollection(text string) ([]*SGF, error) {
+	p := &parser{text: strings.TrimPrefix(text, "\ufeff")}
+	var games []*SGF
+	for {
+		p.skipSpace()
+		if p.eof() {
+			break
+		}
+		tree, err := p.gameTree()
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, &SGF{Root: tree})
+	}
+	if len(games) == 0 {
+		return nil, ErrEmptyCollection
+	}
+	return games, nil
+}
+
+type parser struct {
+	text string
+	pos  int
+}
+
+func (p *parser) gameTree() (*GameTree, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	tree := &GameTree{}
+
+	p.skipSpace()
+	if p.peek() != ';' {
+		return nil, p.errorf("game tree must start with a node")
+	}
+	for p.skipSpace(); p.peek() == ';'; p.skipSpace() {
+		node, err := p.node()
+		if err != nil {
+			return nil, err
+		}
+		tree.Nodes = append(tree.Nodes, node)
+	}
+
+	for p.peek() == '(' {
+		child, err := p.gameTree()
+		if err != nil {
+			return nil, err
+		}
+		tree.Children = append(tree.Children, child)
+		p.skipSpace()
+	}
+
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+func (p *parser) node() (Node, error) {
+	p.pos++ // ';'
+	node := Node{Properties: make(map[string][]string)}
+	for {
+		p.skipSpace()
+		c := p.peek()
+		if !isLetter(c) {
+			return node, nil
+		}
+
+		start := p.pos
+		ident, err := p.propIdent()
+		if err != nil {
+			return node, err
+		}
+		values, err := p.propValues()
+		if err != nil {
+			return node, err
+		}
+		if pointListProperties[ident] {
+			if values, err = ExpandPointList(values); err != nil {
+				return node, p.errorAt(start, err.Error())
+			}
+		}
+		node.Properties[ident] = append(node.Properties[ident], values...)
+	}
+}
+
+// propIdent читает идентификатор свойства. Строчные буквы из старых версий формата отбрасываются.
+func (p *parser) propIdent() (string, error) {
+	start := p.pos
+	var ident strings.Builder
+	for isLetter(p.peek()) {
+		if c := p.peek(); c >= 'A' && c <= 'Z' {
+			ident.WriteByte(c)
+		}
+		p.pos++
+	}
+	if ident.Len() == 0 {
+		return "", p.errorAt(start, "property identifier must contain upper case letters")
+	}
+	return ident.String(), nil
+}
+
+func (p *parser) propValues() ([]string, error) {
+	var values []string
+	for p.skipSpace(); p.peek() == '['; p.skipSpace() {
+		value, err := p.propValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return nil, p.errorf("property has no values")
+	}
+	return values, nil
+}
+
+func (p *parser) propValue() (string, error) {
+	start := p.pos
+	p.pos++ // '['
+	var value strings.Builder
+	for !p.eof() {
+		c := p.text[p.pos]
+		switch c {
+		case ']':
+			p.pos++
+			return value.String(), nil
+		case '\\':
+			p.pos++
+			if p.eof() {
+				return "", p.errorAt(start, "unterminated property value")
+			}
+			// экранированный перевод строки - мягкий перенос, он удаляется
+			if n := p.newlineLen(); n > 0 {
+				p.pos += n
+				continue
+			}
+			value.WriteByte(p.text[p.pos])
+		default:
+			value.WriteByte(c)
+		}
+		p.pos++
+	}
+	return "", p.errorAt(start, "unterminated property value")
+}
+
+// newlineLen возвращает длину перевода строки в текущей позиции (\n, \r, \r\n или \n\r).
+func (p *parser) newlineLen() int {
+	rest := p.text[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "\r\n"), strings.HasPrefix(rest, "\n\r"):
+		return 2
+	case strings.HasPrefix(rest, "\n"), strings.HasPrefix(rest, "\r"):
+		return 1
+	}
+	return 0
+}
+
+func (p *parser) expect(c byte) error {
+	p.skipSpace()
+	if p.eof() {
+		return p.errorf(fmt.Sprintf("expected '%c', got end of input", c))
+	}
+	if p.peek() != c {
+		return p.errorf(fmt.Sprintf("expected '%c', got '%c'", c, p.peek()))
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() {
+		switch p.text[p.pos] {
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.text[p.pos]
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.text)
+}
+
+func (p *parser) errorf(msg string) error {
+	return p.errorAt(p.pos, msg)
+}
+
+func (p *parser) errorAt(offset int, msg string) error {
+	consumed := p.text[:min(offset, len(p.text))]
+	line := strings.Count(consumed, "\n") + 1
+	column := offset - strings.LastIndex(consumed, "\n")
+	return &ParseError{Offset: offset, Line: line, Column: column, Msg: msg}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+// ExpandPointList разворачивает сжатую запись прямоугольников "aa:cc" в отдельные пункты.
+func ExpandPointList(values []string) ([]string, error) {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		from, to, ok := strings.Cut(v, ":")
+		if !ok {
+			result = append(result, v)
+			continue
+		}
+		if !isPoint(from) || !isPoint(to) {
+			return nil, fmt.Errorf("malformed compressed point list %q", v)
+		}
+		x1, x2 := min(from[0], to[0]), max(from[0], to[0])
+		y1, y2 := min(from[1], to[1]), max(from[1], to[1])
+		for y := y1; y <= y2; y++ {
+			for x := x1; x <= x2; x++ {
+				result = append(result, string([]byte{x, y}))
+			}
+		}
+	}
+	return result, nil
+}
+
+func isPoint(s string) bool {
+	return len(s) == 2 && isLetter(s[0]) && isLetter(s[1])
+}
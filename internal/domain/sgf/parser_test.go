@@ -0,0 +1,189 @@
+package sgf
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseProperties(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []map[string][]string // свойства узлов основной линии
+	}{
+		{
+			name: "root and moves",
+			text: "(;FF[4]GM[1]SZ[19];B[pd];W[dp])",
+			want: []map[string][]string{
+				{"FF": {"4"}, "GM": {"1"}, "SZ": {"19"}},
+				{"B": {"pd"}},
+				{"W": {"dp"}},
+			},
+		},
+		{
+			name: "whitespace between tokens",
+			text: " ( ;SZ [9]\n ; B [cc] ) ",
+			want: []map[string][]string{
+				{"SZ": {"9"}},
+				{"B": {"cc"}},
+			},
+		},
+		{
+			name: "escaped bracket and backslash",
+			text: `(;C[a \] b \\ c])`,
+			want: []map[string][]string{
+				{"C": {`a ] b \ c`}},
+			},
+		},
+		{
+			name: "escaped newline is a soft line break",
+			text: "(;C[one\\\ntwo\nthree])",
+			want: []map[string][]string{
+				{"C": {"onetwo\nthree"}},
+			},
+		},
+		{
+			name: "compressed point list",
+			text: "(;AB[aa:bb][dd]AW[ca])",
+			want: []map[string][]string{
+				{"AB": {"aa", "ba", "ab", "bb", "dd"}, "AW": {"ca"}},
+			},
+		},
+		{
+			name: "compressed point list with reversed corners",
+			text: "(;AE[bb:aa])",
+			want: []map[string][]string{
+				{"AE": {"aa", "ba", "ab", "bb"}},
+			},
+		},
+		{
+			name: "colon outside point lists is kept",
+			text: "(;DT[2024-01-01]C[score 3:2])",
+			want: []map[string][]string{
+				{"DT": {"2024-01-01"}, "C": {"score 3:2"}},
+			},
+		},
+		{
+			name: "repeated property is merged",
+			text: "(;AB[aa]AB[bb])",
+			want: []map[string][]string{
+				{"AB": {"aa", "bb"}},
+			},
+		},
+		{
+			name: "lower case letters of old identifiers are dropped",
+			text: "(;FF[4]AddBlack[aa])",
+			want: []map[string][]string{
+				{"FF": {"4"}, "AB": {"aa"}},
+			},
+		},
+		{
+			name: "byte order mark",
+			text: "\uFEFF(;SZ[13])",
+			want: []map[string][]string{
+				{"SZ": {"13"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			nodes := game.MainLine()
+			if len(nodes) != len(tt.want) {
+				t.Fatalf("main line has %d nodes, want %d", len(nodes), len(tt.want))
+			}
+			for i, node := range nodes {
+				if !reflect.DeepEqual(node.Properties, tt.want[i]) {
+					t.Errorf("node %d = %v, want %v", i, node.Properties, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseVariations(t *testing.T) {
+	game, err := Parse("(;SZ[9];B[cc](;W[gg];B[gc])(;W[gc]))")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	root := game.Root
+	if len(root.Nodes) != 2 {
+		t.Fatalf("root has %d nodes, want 2", len(root.Nodes))
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("root has %d variations, want 2", len(root.Children))
+	}
+	if got := root.Children[0].Nodes[1].Properties["B"]; !reflect.DeepEqual(got, []string{"gc"}) {
+		t.Errorf("first variation second move = %v, want [gc]", got)
+	}
+	if got := root.Children[1].Nodes[0].Properties["W"]; !reflect.DeepEqual(got, []string{"gc"}) {
+		t.Errorf("second variation move = %v, want [gc]", got)
+	}
+}
+
+func TestParseCollection(t *testing.T) {
+	games, err := ParseCollection("(;SZ[9])\n(;SZ[13])")
+	if err != nil {
+		t.Fatalf("ParseCollection: %v", err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("got %d games, want 2", len(games))
+	}
+	if got := games[1].RootNode().Properties["SZ"]; !reflect.DeepEqual(got, []string{"13"}) {
+		t.Errorf("second game SZ = %v, want [13]", got)
+	}
+
+	if _, err = ParseCollection("  \n "); !errors.Is(err, ErrEmptyCollection) {
+		t.Errorf("empty collection error = %v, want %v", err, ErrEmptyCollection)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		line   int
+		column int
+	}{
+		{name: "missing opening paren", text: ";B[aa])", line: 1, column: 1},
+		{name: "tree without a node", text: "(B[aa])", line: 1, column: 2},
+		{name: "unterminated value", text: "(;SZ[19]\n;C[abc", line: 2, column: 3},
+		{name: "property without values", text: "(;SZ[19];B)", line: 1, column: 11},
+		{name: "missing closing paren", text: "(;SZ[19]", line: 1, column: 9},
+		{name: "malformed compressed list", text: "(;SZ[9]\n\n;AB[a:cc])", line: 3, column: 2},
+		{name: "identifier without upper case", text: "(;b[aa])", line: 1, column: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.text)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse error = %v, want *ParseError", err)
+			}
+			if parseErr.Line != tt.line || parseErr.Column != tt.column {
+				t.Errorf("error at %d:%d, want %d:%d (%v)", parseErr.Line, parseErr.Column, tt.line, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestExpandPointList(t *testing.T) {
+	got, err := ExpandPointList([]string{"aa:ab", "cc", "dd:ed"})
+	if err != nil {
+		t.Fatalf("ExpandPointList: %v", err)
+	}
+	want := []string{"aa", "ab", "cc", "dd", "ed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandPointList = %v, want %v", got, want)
+	}
+
+	if _, err = ExpandPointList([]string{"aa:c"}); err == nil {
+		t.Error("ExpandPointList accepted a malformed list")
+	}
+}
@@ -0,0 +1,73 @@
+package sgf
+
+import (
+	"sort"
+	"strings"
+)
+
+// propertyOrder фиксированный порядок свойств при записи, остальные свойства пишутся следом по алфавиту.
+var propertyOrder = []string{"FF", "GM", "SZ", "PB", "PW", "DT", "RE", "KM", "TM", "OT", "RU", "HA", "AB", "AW", "PL", "C", "B", "W"}
+
+// Serialize записывает партию в SGF, экранируя в значениях символы ']' и '\'.
+func Serialize(s *SGF) string {
+	var builder strings.Builder
+	builder.WriteString("(")
+	serializeGameTree(&builder, s.Root)
+	builder.WriteString(")")
+	return builder.String()
+}
+
+func serializeGameTree(builder *strings.Builder, tree *GameTree) {
+	for _, node := range tree.Nodes {
+		builder.WriteString(";")
+
+		used := make(map[string]bool)
+		for _, key := range propertyOrder {
+			if values, ok := node.Properties[key]; ok {
+				used[key] = true
+				writeProperty(builder, key, values)
+			}
+		}
+
+		rest := make([]string, 0, len(node.Properties)-len(used))
+		for key := range node.Properties {
+			if !used[key] {
+				rest = append(rest, key)
+			}
+		}
+		sort.Strings(rest)
+		for _, key := range rest {
+			writeProperty(builder, key, node.Properties[key])
+		}
+	}
+
+	for _, child := range tree.Children {
+		builder.WriteString("(")
+		serializeGameTree(builder, child)
+		builder.WriteString(")")
+	}
+}
+
+// writeProperty пишет свойство один раз со всеми значениями подряд, например AB[dd][pp].
+func writeProperty(builder *strings.Builder, key string, values []string) {
+	builder.WriteString(key)
+	for _, v := range values {
+		builder.WriteString("[")
+		builder.WriteString(escapeValue(v))
+		builder.WriteString("]")
+	}
+}
+
+func escapeValue(v string) string {
+	if !strings.ContainsAny(v, `]\`) {
+		return v
+	}
+	var builder strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == ']' || v[i] == '\\' {
+			builder.WriteByte('\\')
+		}
+		builder.WriteByte(v[i])
+	}
+	return builder.String()
+}
@@ -0,0 +1,62 @@
+package sgf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSerializeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "root and moves", text: "(;FF[4]GM[1]SZ[19]KM[6.5]RU[Japanese];B[pd];W[dp];B[])"},
+		{name: "handicap stones", text: "(;FF[4]GM[1]SZ[19]HA[2]AB[dp][pd]PL[W];W[dd])"},
+		{name: "escaped comment", text: `(;FF[4]C[a \] b \\ c];C[ok]B[aa])`},
+		{name: "variations", text: "(;SZ[9];B[cc](;W[gg];B[gc])(;W[gc]))"},
+		{name: "unknown properties in sorted order", text: "(;FF[4]AP[team_exe]GN[test]XZ[1];B[aa]BL[30]OB[2])"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := Serialize(game); got != tt.text {
+				t.Errorf("Serialize = %s, want %s", got, tt.text)
+			}
+		})
+	}
+}
+
+func TestSerializeOrdersProperties(t *testing.T) {
+	game := &SGF{Root: &GameTree{Nodes: []Node{{Properties: map[string][]string{
+		"ZZ": {"1"}, "W": {"aa"}, "AP": {"app"}, "SZ": {"9"}, "FF": {"4"}, "MN": {"2"}, "C": {"x"},
+	}}}}}
+	want := "(;FF[4]SZ[9]C[x]W[aa]AP[app]MN[2]ZZ[1])"
+	// порядок обхода map случаен, поэтому результат проверяется несколько раз
+	for i := 0; i < 20; i++ {
+		if got := Serialize(game); got != want {
+			t.Fatalf("Serialize = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestSerializeEscapes(t *testing.T) {
+	game := &SGF{Root: &GameTree{Nodes: []Node{{Properties: map[string][]string{
+		"C": {`close ] and slash \`},
+	}}}}}
+	text := Serialize(game)
+	if want := `(;C[close \] and slash \\])`; text != want {
+		t.Fatalf("Serialize = %s, want %s", text, want)
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !reflect.DeepEqual(parsed.Root.Nodes, game.Root.Nodes) {
+		t.Errorf("round trip = %v, want %v", parsed.Root.Nodes, game.Root.Nodes)
+	}
+}
@@ -0,0 +1,60 @@
+package sgf
+
+// RootNode возвращает корневой узел партии со свойствами игры (SZ, KM, RE и т.д.).
+func (s *SGF) RootNode() *Node {
+	if s.Root == nil || len(s.Root.Nodes) == 0 {
+		return nil
+	}
+	return &s.Root.Nodes[0]
+}
+
+// MainLine возвращает узлы основной линии партии, проходя по первым вариантам.
+func (s *SGF) MainLine() []Node {
+	var nodes []Node
+	for tree := s.Root; tree != nil; tree = firstChild(tree) {
+		nodes = append(nodes, tree.Nodes...)
+	}
+	return nodes
+}
+
+// AppendNode добавляет узел в конец основной линии.
+func (s *SGF) AppendNode(node Node) {
+	tree := s.Root
+	for child := firstChild(tree); child != nil; child = firstChild(tree) {
+		tree = child
+	}
+	tree.Nodes = append(tree.Nodes, node)
+}
+
+// SetProperty заменяет значения свойства узла.
+func (n *Node) SetProperty(key string, values ...string) {
+	if n.Properties == nil {
+		n.Properties = make(map[string][]string)
+	}
+	n.Properties[key] = values
+}
+
+func firstChild(tree *GameTree) *GameTree {
+	if len(tree.Children) == 0 {
+		return nil
+	}
+	return tree.Children[0]
+}
+
+// RemoveLastNodes удаляет n последних узлов основной линии. Корневой узел не удаляется.
+func (s *SGF) RemoveLastNodes(n int) {
+	for ; n > 0; n-- {
+		var parent *GameTree
+		tree := s.Root
+		for child := firstChild(tree); child != nil; child = firstChild(tree) {
+			parent, tree = tree, child
+		}
+		if parent == nil && len(tree.Nodes) <= 1 {
+			return
+		}
+		tree.Nodes = tree.Nodes[:len(tree.Nodes)-1]
+		if len(tree.Nodes) == 0 && parent != nil {
+			parent.Children = parent.Children[1:]
+		}
+	}
+}
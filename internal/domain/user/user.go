@@ -1,29 +1,111 @@
 package user
 
-import "time"
+import (
+	"strconv"
+	"time"
+
+	"team_exe/internal/domain/rank"
+	"team_exe/internal/domain/rating"
+)
 
 // @name User
 type User struct {
-	ID             string            `json:"id" bson:"_id,omitempty"`
-	Username       string            `json:"Username" bson:"username"`
-	Email          string            `json:"email" bson:"email"`
-	CreatedAt      time.Time         `json:"created_at" bson:"created_at"`
-	UpdatedAt      time.Time         `json:"updated_at" bson:"updated_at"`
-	Rating         int               `json:"rating" bson:"rating"`
-	CurrentGameKey string            `json:"current_game_key,omitempty" bson:"current_game_key,omitempty"`
-	AvatarURL      string            `json:"avatar_url,omitempty" bson:"avatar_url,omitempty"`
-	Status         string            `json:"status,omitempty" bson:"status,omitempty"`
-	SocialLinks    map[string]string `json:"social_links,omitempty" bson:"social_links,omitempty"`
-	Coins          int               `json:"coins" bson:"coins"`
-	Statistic      UserStatistic     `json:"statistic" bson:"statistic"`
-	PasswordHash   string            `bson:"password_hash"`
-	PasswordSalt   string            `bson:"password_salt"`
+	ID               string            `json:"id" bson:"_id,omitempty"`
+	Username         string            `json:"Username" bson:"username"`
+	Email            string            `json:"email" bson:"email"`
+	CreatedAt        time.Time         `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at" bson:"updated_at"`
+	Rating           float64           `json:"rating" bson:"rating"`
+	RatingDeviation  float64           `json:"rating_deviation" bson:"rating_deviation"`
+	RatingVolatility float64           `json:"rating_volatility" bson:"rating_volatility"`
+	Rank             *rank.Rank        `json:"rank,omitempty" bson:"-"` // выводится из рейтинга, не хранится
+	CurrentGameKey   string            `json:"current_game_key,omitempty" bson:"current_game_key,omitempty"`
+	AvatarURL        string            `json:"avatar_url,omitempty" bson:"avatar_url,omitempty"`
+	Status           string            `json:"status,omitempty" bson:"status,omitempty"`
+	SocialLinks      map[string]string `json:"social_links,omitempty" bson:"social_links,omitempty"`
+	Coins            int               `json:"coins" bson:"coins"`
+	Statistic        UserStatistic     `json:"statistic" bson:"statistic"`
+	PasswordHash     string            `bson:"password_hash"`
+	PasswordSalt     string            `bson:"password_salt"`
+}
+
+// Glicko возвращает рейтинг пользователя по Glicko-2. У пользователей, зарегистрированных
+// до появления рейтинга, он начальный.
+func (u User) Glicko() rating.Rating {
+	return rating.Rating{
+		Rating:     u.Rating,
+		Deviation:  u.RatingDeviation,
+		Volatility: u.RatingVolatility,
+	}.OrDefault()
+}
+
+// FillRank заполняет ранг пользователя по его рейтингу.
+func (u *User) FillRank(scale rank.Scale) {
+	r := scale.FromRating(u.Glicko().Rating)
+	u.Rank = &r
 }
 
 // @name UserStatistic
 type UserStatistic struct {
-	Wins         int      `json:"wins" bson:"wins"`
-	Losses       int      `json:"losses" bson:"losses"`
-	Draws        int      `json:"draws" bson:"draws"`
-	Achievements []string `json:"achievements,omitempty" bson:"achievements,omitempty"`
+	Wins         int               `json:"wins" bson:"wins"`
+	Losses       int               `json:"losses" bson:"losses"`
+	Draws        int               `json:"draws" bson:"draws"`
+	AsBlack      Record            `json:"as_black" bson:"as_black"`
+	AsWhite      Record            `json:"as_white" bson:"as_white"`
+	BySize       map[string]Record `json:"by_size,omitempty" bson:"by_size,omitempty"` // ключ - размер доски, например "19"
+	Achievements []string          `json:"achievements,omitempty" bson:"achievements,omitempty"`
+}
+
+// @name Record
+type Record struct {
+	Wins   int `json:"wins" bson:"wins"`
+	Losses int `json:"losses" bson:"losses"`
+	Draws  int `json:"draws" bson:"draws"`
+}
+
+const (
+	OutcomeWin  = "win"
+	OutcomeLoss = "loss"
+	OutcomeDraw = "draw"
+)
+
+// GameOutcome итог завершённой партии для одного из игроков.
+type GameOutcome struct {
+	UserID    string
+	Color     string // B или W
+	BoardSize int
+	Outcome   string // win, loss или draw
+}
+
+// Apply учитывает итог партии в общей статистике, по цвету и по размеру доски.
+func (s *UserStatistic) Apply(o GameOutcome) {
+	total := Record{Wins: s.Wins, Losses: s.Losses, Draws: s.Draws}
+	total.add(o.Outcome)
+	s.Wins, s.Losses, s.Draws = total.Wins, total.Losses, total.Draws
+
+	switch o.Color {
+	case "B":
+		s.AsBlack.add(o.Outcome)
+	case "W":
+		s.AsWhite.add(o.Outcome)
+	}
+
+	if s.BySize == nil {
+		s.BySize = make(map[string]Record)
+	}
+	size := strconv.Itoa(o.BoardSize)
+	record := s.BySize[size]
+	record.add(o.Outcome)
+	s.BySize[size] = record
+}
+
+func (r *Record) add(outcome string) {
+	switch outcome {
+	case OutcomeWin:
+		r.Wins++
+	case OutcomeLoss:
+		r.Losses++
+	case OutcomeDraw:
+		r.Draws++
+	}
 }
@@ -3,12 +3,33 @@ package errors
 import "errors"
 
 var (
-	ErrUserNotFound     = errors.New("user with provided username was not found")
-	ErrWrongPassword    = errors.New("wrong password")
-	ErrSessionNotFound  = errors.New("session was not found")
-	ErrCreateGameFailed = errors.New("create game failed")
-	ErrJoinGameFailed   = errors.New("join game failed")
-	ErrGameNotFound     = errors.New("game not found")
-	ErrUserExists       = errors.New("user already exists")
-	ErrInternal         = errors.New("internal error")
+	ErrUserNotFound        = errors.New("user with provided username was not found")
+	ErrWrongPassword       = errors.New("wrong password")
+	ErrSessionNotFound     = errors.New("session was not found")
+	ErrCreateGameFailed    = errors.New("create game failed")
+	ErrJoinGameFailed      = errors.New("join game failed")
+	ErrGameNotFound        = errors.New("game not found")
+	ErrUserExists          = errors.New("user already exists")
+	ErrInternal            = errors.New("internal error")
+	ErrGameNotStarted      = errors.New("game has not started yet")
+	ErrNotYourColor        = errors.New("move color does not match player color")
+	ErrNotAPlayer          = errors.New("user is not a player of this game")
+	ErrGameNotInPlay       = errors.New("game is not in play")
+	ErrUnknownAction       = errors.New("unknown game action")
+	ErrNotScoringPhase     = errors.New("game is not in scoring phase")
+	ErrUnknownHandicapType = errors.New("unknown handicap placement type")
+	ErrHandicapPending     = errors.New("handicap stones are not placed yet")
+	ErrUndoDisabled        = errors.New("undo is disabled in this game")
+	ErrUndoLimit           = errors.New("undo limit is exhausted")
+	ErrNothingToUndo       = errors.New("there is no move to undo")
+	ErrNoUndoRequest       = errors.New("there is no undo request to answer")
+	ErrAlreadyInGame       = errors.New("user already participates in a game")
+	ErrBadBoardSize        = errors.New("unsupported board size")
+	ErrGameNotPublic       = errors.New("game is not open to spectators")
+	ErrSpectatorLimit      = errors.New("spectator limit is reached")
+	ErrBadSpectatorLimit   = errors.New("spectator limit must not be negative")
+	ErrKibitzForPlayers    = errors.New("players cannot write to the spectators' channel during the game")
+	ErrBadBotColor         = errors.New("bot color must be B or W")
+	ErrBotBoardSize        = errors.New("bot games are played on boards up to 19x19")
+	ErrBotResigned         = errors.New("bot resigned")
 )
@@ -11,8 +11,10 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"team_exe/internal/adapters"
+	"team_exe/internal/domain/rating"
 	"team_exe/internal/domain/user"
 	errs "team_exe/internal/errors"
 )
@@ -60,14 +62,16 @@ func (m *MongoUserStorage) CreateUser(username, email, password string) (user.Us
 
 	collection := m.adapter.Database.Collection("users")
 	newUser := user.User{
-		Username:       username,
-		Email:          email,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
-		Rating:         0,
-		CurrentGameKey: "",
-		AvatarURL:      "",
-		Status:         "",
+		Username:         username,
+		Email:            email,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+		Rating:           rating.DefaultRating,
+		RatingDeviation:  rating.DefaultDeviation,
+		RatingVolatility: rating.DefaultVolatility,
+		CurrentGameKey:   "",
+		AvatarURL:        "",
+		Status:           "",
 		Statistic: user.UserStatistic{
 			Wins:         0,
 			Losses:       0,
@@ -112,7 +116,70 @@ func (m *MongoUserStorage) GetUserByID(ctx context.Context, userID string) (user
 	return result, nil
 }
 
-func (m *MongoUserStorage) AddLose(ctx context.Context, userID string) error {
+// RecordGameResults учитывает итог партии в статистике обоих игроков одним пакетом атомарных $inc.
+// Пакет не транзакционный: при ошибке часть игроков может быть уже учтена. Ключ партии
+// запоминается в counted_games, поэтому вызывающий должен повторить запись при ошибке:
+// повтор учтёт только пропущенных игроков.
+func (m *MongoUserStorage) RecordGameResults(ctx context.Context, gameKey string, outcomes []user.GameOutcome) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	models := make([]mongo.WriteModel, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		userObjID, err := primitive.ObjectIDFromHex(outcome.UserID)
+		if err != nil {
+			return fmt.Errorf("invalid userID format: %w", err)
+		}
+		inc := bson.M{}
+		for _, field := range statisticCounters(outcome) {
+			inc[field] = 1
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": userObjID, "counted_games": bson.M{"$ne": gameKey}}).
+			SetUpdate(bson.M{
+				"$inc":      inc,
+				"$addToSet": bson.M{"counted_games": gameKey},
+			}))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+
+	collection := m.adapter.Database.Collection("users")
+	_, err := collection.BulkWrite(ctx, models)
+	return err
+}
+
+// statisticCounters возвращает поля статистики, которые увеличивает итог партии.
+func statisticCounters(outcome user.GameOutcome) []string {
+	var counter string
+	switch outcome.Outcome {
+	case user.OutcomeWin:
+		counter = "wins"
+	case user.OutcomeLoss:
+		counter = "losses"
+	case user.OutcomeDraw:
+		counter = "draws"
+	default:
+		return nil
+	}
+
+	fields := []string{
+		"statistic." + counter,
+		fmt.Sprintf("statistic.by_size.%d.%s", outcome.BoardSize, counter),
+	}
+	switch outcome.Color {
+	case "B":
+		fields = append(fields, "statistic.as_black."+counter)
+	case "W":
+		fields = append(fields, "statistic.as_white."+counter)
+	}
+	return fields
+}
+
+// ReplaceStatistic заменяет счётчики статистики пересчитанными по истории партий.
+// Достижения пользователя не затрагиваются.
+func (m *MongoUserStorage) ReplaceStatistic(ctx context.Context, userID string, statistic user.UserStatistic, gameKeys []string) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 	userObjID, err := primitive.ObjectIDFromHex(userID)
@@ -120,21 +187,103 @@ func (m *MongoUserStorage) AddLose(ctx context.Context, userID string) error {
 		return fmt.Errorf("invalid userID format: %w", err)
 	}
 
-	filter := bson.M{"_id": userObjID}
+	update := bson.M{
+		"$set": bson.M{
+			"statistic.wins":     statistic.Wins,
+			"statistic.losses":   statistic.Losses,
+			"statistic.draws":    statistic.Draws,
+			"statistic.as_black": statistic.AsBlack,
+			"statistic.as_white": statistic.AsWhite,
+			"statistic.by_size":  statistic.BySize,
+			"counted_games":      gameKeys,
+		},
+	}
 	collection := m.adapter.Database.Collection("users")
+	res, err := collection.UpdateOne(ctx, bson.M{"_id": userObjID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("user with id %s not found", userID)
+	}
+	return nil
+}
 
-	var result user.User
-	if err = collection.FindOne(ctx, filter).Decode(&result); err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return fmt.Errorf("user with id %s not found", userID)
+// ApplyRatings сохраняет новые рейтинги обоих игроков рейтинговой партии и записи их истории.
+// Сначала пакетом пишется история, затем пакетом рейтинги. Пакеты не транзакционные, но
+// идемпотентны: запись истории уникальна по игроку и партии, а рейтинг игрока меняется
+// не более одного раза, пока ключ партии не попал в rated_games. Поэтому при ошибке
+// вызывающий должен повторить запись с теми же записями истории.
+func (m *MongoUserStorage) ApplyRatings(ctx context.Context, gameKey string, entries []rating.HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	history := make([]mongo.WriteModel, 0, len(entries))
+	users := make([]mongo.WriteModel, 0, len(entries))
+	for _, entry := range entries {
+		userObjID, err := primitive.ObjectIDFromHex(entry.UserID)
+		if err != nil {
+			return fmt.Errorf("invalid userID format: %w", err)
 		}
+		history = append(history, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"user_id": entry.UserID, "game_key": gameKey}).
+			SetUpdate(bson.M{"$setOnInsert": entry}).
+			SetUpsert(true))
+		users = append(users, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": userObjID, "rated_games": bson.M{"$ne": gameKey}}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"rating":            entry.After.Rating,
+					"rating_deviation":  entry.After.Deviation,
+					"rating_volatility": entry.After.Volatility,
+				},
+				"$addToSet": bson.M{"rated_games": gameKey},
+			}))
+	}
+
+	if _, err := m.adapter.Database.Collection("rating_history").BulkWrite(ctx, history); err != nil {
 		return err
 	}
-	result.Statistic.Losses++
-	update := bson.D{{"$set", bson.D{{"statistic", result.Statistic}}}}
-	_, err = collection.UpdateOne(ctx, filter, update)
+	_, err := m.adapter.Database.Collection("users").BulkWrite(ctx, users)
+	return err
+}
+
+// GetGameRatingEntries возвращает записи истории рейтинга, уже сохранённые для партии.
+func (m *MongoUserStorage) GetGameRatingEntries(ctx context.Context, gameKey string) ([]rating.HistoryEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cursor, err := m.adapter.Database.Collection("rating_history").Find(ctx, bson.M{"game_key": gameKey})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := make([]rating.HistoryEntry, 0)
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetRatingHistory возвращает изменения рейтинга пользователя в порядке времени.
+func (m *MongoUserStorage) GetRatingHistory(ctx context.Context, userID string) ([]rating.HistoryEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := m.adapter.Database.Collection("rating_history").Find(ctx, bson.M{"user_id": userID}, opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	defer cursor.Close(ctx)
+
+	history := make([]rating.HistoryEntry, 0)
+	if err = cursor.All(ctx, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
 }
@@ -15,6 +15,8 @@ import (
 	"go.uber.org/zap"
 	"net/http"
 	"team_exe/internal/bootstrap"
+	"team_exe/internal/domain/chat"
+	"team_exe/internal/domain/clock"
 	"team_exe/internal/domain/game"
 	"team_exe/internal/domain/user"
 	"team_exe/internal/statuses"
@@ -90,6 +92,17 @@ func (g *GameRepository) PutGameToMongoDatabase(ctx context.Context, gameData ga
 	return true
 }
 
+// DeleteGame удаляет партию и её SGF.
+func (g *GameRepository) DeleteGame(ctx context.Context, gameKey string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := g.mongo.Collection("games").DeleteOne(ctx, bson.M{"game_key": gameKey}); err != nil {
+		return err
+	}
+	return g.redis.Del(ctx, gameKey).Err()
+}
+
 func (g *GameRepository) AddPlayer(ctx context.Context, userId string, gameKey string) (game.Game, bool) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -238,6 +251,133 @@ func (g *GameRepository) LeaveGameBySecretKey(ctx context.Context, secretKey str
 	return nil
 }
 
+// AppendMove дописывает принятый ход в журнал ходов игры и сохраняет очередь хода и состояние часов.
+func (g *GameRepository) AppendMove(ctx context.Context, gameKey string, move game.Move, whoIsNext string, clockState *clock.State) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := g.mongo.Collection("games")
+	set := bson.M{"who_is_next": whoIsNext}
+	if clockState != nil {
+		set["clock_state"] = clockState
+	}
+	update := bson.M{
+		"$push": bson.M{"moves": move},
+		"$set":  set,
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"game_key": gameKey}, update)
+	if err != nil {
+		g.log.Error("ошибка при сохранении хода:", err)
+		return err
+	}
+	return nil
+}
+
+func (g *GameRepository) AppendChatMessage(ctx context.Context, gameKey string, message chat.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := g.mongo.Collection("games")
+	_, err := collection.UpdateOne(ctx, bson.M{"game_key": gameKey}, bson.M{"$push": bson.M{"chat": message}})
+	if err != nil {
+		g.log.Error("ошибка при сохранении сообщения чата:", err)
+		return err
+	}
+	return nil
+}
+
+func (g *GameRepository) SetHandicapStones(ctx context.Context, gameKey string, stones []string, whoIsNext string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := g.mongo.Collection("games")
+	update := bson.M{
+		"$set": bson.M{
+			"handicap_stones": stones,
+			"who_is_next":     whoIsNext,
+		},
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"game_key": gameKey}, update)
+	if err != nil {
+		g.log.Error("ошибка при сохранении камней форы:", err)
+		return err
+	}
+	return nil
+}
+
+func (g *GameRepository) ApplyUndo(ctx context.Context, gameKey string, moves []game.Move, whoIsNext string, undosUsed map[string]int) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := g.mongo.Collection("games")
+	update := bson.M{
+		"$set": bson.M{
+			"moves":       moves,
+			"who_is_next": whoIsNext,
+			"undos_used":  undosUsed,
+		},
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"game_key": gameKey}, update)
+	if err != nil {
+		g.log.Error("ошибка при сохранении отмены хода:", err)
+		return err
+	}
+	return nil
+}
+
+// SaveScoring сохраняет пометки мёртвых камней и согласия игроков с подсчётом.
+func (g *GameRepository) SaveScoring(ctx context.Context, gameKey string, deadStones []string, scoreAccepted map[string]bool) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := g.mongo.Collection("games")
+	update := bson.M{
+		"$set": bson.M{
+			"dead_stones":    deadStones,
+			"score_accepted": scoreAccepted,
+		},
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"game_key": gameKey}, update)
+	if err != nil {
+		g.log.Error("ошибка при сохранении подсчёта очков:", err)
+		return err
+	}
+	return nil
+}
+
+func (g *GameRepository) UpdateGameStatus(ctx context.Context, gameKey string, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := g.mongo.Collection("games")
+	_, err := collection.UpdateOne(ctx, bson.M{"game_key": gameKey}, bson.M{"$set": bson.M{"status": status}})
+	if err != nil {
+		g.log.Error("ошибка при обновлении статуса игры:", err)
+		return err
+	}
+	return nil
+}
+
+func (g *GameRepository) FinishGame(ctx context.Context, gameKey string, result game.Result, sgfText string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := g.mongo.Collection("games")
+	update := bson.M{
+		"$set": bson.M{
+			"status": statuses.StatusCompleted,
+			"result": result,
+			"sgf":    sgfText,
+		},
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"game_key": gameKey}, update)
+	if err != nil {
+		g.log.Error("ошибка при завершении игры:", err)
+		return err
+	}
+	return nil
+}
+
 func (g *GameRepository) CalculateUserColor(ctx context.Context, gameKey string, userID string) string {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -282,9 +422,18 @@ func (g *GameRepository) GetGameByGameKey(ctx context.Context, gameKey string) g
 	return result
 }
 
+// defaultSgfTTL время жизни SGF идущей партии в Redis, если SGF_TTL не задан.
+const defaultSgfTTL = 7 * 24 * time.Hour
+
+// SaveSGFToRedis сохраняет SGF партии. Срок хранения продлевается с каждой записью,
+// а заброшенные партии со временем удаляются: их ходы всё равно есть в MongoDB.
 func (g *GameRepository) SaveSGFToRedis(key string, sgfText string) error {
 	ctx := context.Background()
-	return g.redis.Set(ctx, key, sgfText, 0).Err()
+	ttl := g.cfg.SgfTTL
+	if ttl <= 0 {
+		ttl = defaultSgfTTL
+	}
+	return g.redis.Set(ctx, key, sgfText, ttl).Err()
 }
 
 func (g *GameRepository) LoadSGFFromRedis(key string) (string, error) {
@@ -292,12 +441,15 @@ func (g *GameRepository) LoadSGFFromRedis(key string) (string, error) {
 	return g.redis.Get(ctx, key).Result()
 }
 
-func (g *GameRepository) GetAllActiveGames() ([]game.Game, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// GetAllActiveGames возвращает незавершённые игры, в которых заняты оба места.
+func (g *GameRepository) GetAllActiveGames(ctx context.Context) ([]game.Game, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 	collection := g.mongo.Collection("games")
 	filter := bson.M{
-		"statuses": "active",
+		"status":       bson.M{"$ne": statuses.StatusCompleted},
+		"player_black": bson.M{"$nin": bson.A{"", nil}},
+		"player_white": bson.M{"$nin": bson.A{"", nil}},
 	}
 	var result []game.Game
 	cursor, err := collection.Find(ctx, filter)
@@ -320,6 +472,33 @@ func (g *GameRepository) GetAllActiveGames() ([]game.Game, error) {
 	return result, nil
 }
 
+// GetCompletedGamesByUserId возвращает завершённые игры, в которых участвовал пользователь.
+func (g *GameRepository) GetCompletedGamesByUserId(ctx context.Context, userID string) ([]game.Game, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	collection := g.mongo.Collection("games")
+	filter := bson.M{
+		"status": statuses.StatusCompleted,
+		"$or": []bson.M{
+			{"player_black": userID},
+			{"player_white": userID},
+		},
+	}
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		g.log.Error(err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []game.Game
+	if err = cursor.All(ctx, &result); err != nil {
+		g.log.Error(err)
+		return nil, err
+	}
+	return result, nil
+}
+
 func (g *GameRepository) HasUserActiveGameByUserId(ctx context.Context, userID string) (bool, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
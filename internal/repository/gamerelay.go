@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	gameRelayChannel = "game:relay:"
+	gameLeaseKey     = "game:lease:"
+)
+
+// acquireLeaseScript продлевает аренду, если её держит token, или берёт свободную аренду.
+var acquireLeaseScript = redis.NewScript(`
+local holder = redis.call("GET", KEYS[1])
+if holder == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+if not holder then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// GameRelayRepository связывает экземпляры сервера, держащие сокеты одной партии:
+// рассылает сообщения партии через канал Redis и хранит аренду партии.
+type GameRelayRepository struct {
+	log   *zap.SugaredLogger
+	redis *redis.Client
+}
+
+func NewGameRelayRepository(log *zap.SugaredLogger, redis *redis.Client) *GameRelayRepository {
+	return &GameRelayRepository{log: log, redis: redis}
+}
+
+// Publish отправляет сообщение всем экземплярам, подписанным на партию.
+func (r *GameRelayRepository) Publish(ctx context.Context, gameKey string, data []byte) error {
+	return r.redis.Publish(ctx, gameRelayChannel+gameKey, data).Err()
+}
+
+// Subscribe подписывается на сообщения партии и передаёт их в handle, пока не вызвана
+// возвращённая функция отмены. Возвращает управление, когда подписка подтверждена.
+func (r *GameRelayRepository) Subscribe(ctx context.Context, gameKey string, handle func([]byte)) (func(), error) {
+	sub := r.redis.Subscribe(ctx, gameRelayChannel+gameKey)
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	go func() {
+		for msg := range sub.Channel() {
+			handle([]byte(msg.Payload))
+		}
+	}()
+	return func() {
+		if err := sub.Close(); err != nil {
+			r.log.Error("Ошибка отписки от канала партии:", err)
+		}
+	}, nil
+}
+
+// AcquireLease берёт или продлевает аренду партии для token на ttl.
+// Возвращает false, если партию арендует другой экземпляр.
+func (r *GameRelayRepository) AcquireLease(ctx context.Context, gameKey, token string, ttl time.Duration) (bool, error) {
+	acquired, err := acquireLeaseScript.Run(ctx, r.redis, []string{gameLeaseKey + gameKey}, token, ttl.Milliseconds()).Int()
+	return acquired == 1, err
+}
+
+// ReleaseLease снимает аренду партии, если её держит token.
+func (r *GameRelayRepository) ReleaseLease(ctx context.Context, gameKey, token string) error {
+	return unlockScript.Run(ctx, r.redis, []string{gameLeaseKey + gameKey}, token).Err()
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"team_exe/internal/domain/matchmaking"
+)
+
+const (
+	matchmakingQueueKey   = "matchmaking:queue"
+	matchmakingLockKey    = "matchmaking:lock"
+	matchmakingMatchKey   = "matchmaking:match:"
+	matchmakingMatchTTL   = 10 * time.Minute
+	matchmakingLockExpiry = 30 * time.Second
+)
+
+// takePairScript убирает из очереди обе заявки, только если обе ещё в ней.
+var takePairScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 and redis.call("HEXISTS", KEYS[1], ARGV[2]) == 1 then
+	redis.call("HDEL", KEYS[1], ARGV[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// unlockScript снимает блокировку, только если её держит этот экземпляр.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// MatchmakingRepository хранит очередь подбора соперников в Redis, общую для всех экземпляров сервера.
+type MatchmakingRepository struct {
+	log   *zap.SugaredLogger
+	redis *redis.Client
+}
+
+func NewMatchmakingRepository(log *zap.SugaredLogger, redis *redis.Client) *MatchmakingRepository {
+	return &MatchmakingRepository{log: log, redis: redis}
+}
+
+// Enqueue ставит заявку в очередь, заменяя прежнюю заявку того же игрока.
+func (m *MatchmakingRepository) Enqueue(ctx context.Context, req matchmaking.Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return m.redis.HSet(ctx, matchmakingQueueKey, req.UserID, data).Err()
+}
+
+// Dequeue убирает заявку игрока из очереди. Возвращает false, если заявки не было.
+func (m *MatchmakingRepository) Dequeue(ctx context.Context, userID string) (bool, error) {
+	removed, err := m.redis.HDel(ctx, matchmakingQueueKey, userID).Result()
+	return removed > 0, err
+}
+
+// GetRequest возвращает заявку игрока или nil, если он не в очереди.
+func (m *MatchmakingRepository) GetRequest(ctx context.Context, userID string) (*matchmaking.Request, error) {
+	data, err := m.redis.HGet(ctx, matchmakingQueueKey, userID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var req matchmaking.Request
+	if err = json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// ListRequests возвращает все заявки очереди.
+func (m *MatchmakingRepository) ListRequests(ctx context.Context) ([]matchmaking.Request, error) {
+	values, err := m.redis.HVals(ctx, matchmakingQueueKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]matchmaking.Request, 0, len(values))
+	for _, value := range values {
+		var req matchmaking.Request
+		if err = json.Unmarshal([]byte(value), &req); err != nil {
+			m.log.Error("повреждённая заявка в очереди подбора:", err)
+			continue
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// TakePair забирает из очереди заявки двух игроков. Возвращает false, если один из них уже вышел из очереди.
+func (m *MatchmakingRepository) TakePair(ctx context.Context, first, second string) (bool, error) {
+	taken, err := takePairScript.Run(ctx, m.redis, []string{matchmakingQueueKey}, first, second).Int()
+	return taken == 1, err
+}
+
+// SaveMatch запоминает созданную для игрока партию, пока он не заберёт её через статус.
+func (m *MatchmakingRepository) SaveMatch(ctx context.Context, userID string, match matchmaking.Match) error {
+	data, err := json.Marshal(match)
+	if err != nil {
+		return err
+	}
+	return m.redis.Set(ctx, matchmakingMatchKey+userID, data, matchmakingMatchTTL).Err()
+}
+
+// GetMatch возвращает созданную для игрока партию или nil.
+func (m *MatchmakingRepository) GetMatch(ctx context.Context, userID string) (*matchmaking.Match, error) {
+	data, err := m.redis.Get(ctx, matchmakingMatchKey+userID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var match matchmaking.Match
+	if err = json.Unmarshal(data, &match); err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
+
+// ClearMatch забывает созданную для игрока партию.
+func (m *MatchmakingRepository) ClearMatch(ctx context.Context, userID string) error {
+	return m.redis.Del(ctx, matchmakingMatchKey+userID).Err()
+}
+
+// Lock берёт блокировку подбора, чтобы пары сводил только один экземпляр сервера.
+func (m *MatchmakingRepository) Lock(ctx context.Context, owner string) (bool, error) {
+	return m.redis.SetNX(ctx, matchmakingLockKey, owner, matchmakingLockExpiry).Result()
+}
+
+// Unlock снимает блокировку подбора, взятую owner.
+func (m *MatchmakingRepository) Unlock(ctx context.Context, owner string) error {
+	return unlockScript.Run(ctx, m.redis, []string{matchmakingLockKey}, owner).Err()
+}
@@ -2,3 +2,4 @@ package statuses
 
 const StatusWaitOpponent = "wait_of_the_opponent"
 const StatusCompleted = "completed"
+const StatusScoring = "scoring" // оба игрока спасовали, партия в фазе подсчёта
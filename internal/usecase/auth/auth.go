@@ -3,7 +3,10 @@ package auth
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"team_exe/internal/domain/rank"
+	"team_exe/internal/domain/rating"
 	"team_exe/internal/domain/user"
 	"team_exe/internal/errors"
 	"team_exe/internal/random"
@@ -20,7 +23,11 @@ type UserStorage interface {
 	GetUser(username string) (user.User, bool)
 	GetUserByID(ctx context.Context, userID string) (user.User, error)
 	CreateUser(username, email, password string) (user.User, error)
-	AddLose(ctx context.Context, userID string) error
+	RecordGameResults(ctx context.Context, gameKey string, outcomes []user.GameOutcome) error
+	ReplaceStatistic(ctx context.Context, userID string, statistic user.UserStatistic, gameKeys []string) error
+	ApplyRatings(ctx context.Context, gameKey string, entries []rating.HistoryEntry) error
+	GetGameRatingEntries(ctx context.Context, gameKey string) ([]rating.HistoryEntry, error)
+	GetRatingHistory(ctx context.Context, userID string) ([]rating.HistoryEntry, error)
 }
 
 // SessionStorage описывает операции над сессиями (чтение, запись, удаление).
@@ -67,12 +74,17 @@ func (a *UserUsecaseHandler) GetUserBySessionId(ctx context.Context, sessionID s
 	if !found {
 		return user.User{}, fmt.Errorf("user not found by session id: %s", sessionID)
 	}
-	return a.userStorage.GetUserByID(ctx, userID)
+	return a.GetUserByUserId(ctx, userID)
 }
 
-// GetUserByUserId возвращает пользователя по его userID.
+// GetUserByUserId возвращает пользователя по его userID вместе с рангом.
 func (a *UserUsecaseHandler) GetUserByUserId(ctx context.Context, userID string) (user.User, error) {
-	return a.userStorage.GetUserByID(ctx, userID)
+	found, err := a.userStorage.GetUserByID(ctx, userID)
+	if err != nil {
+		return user.User{}, err
+	}
+	found.FillRank(rank.DefaultScale)
+	return found, nil
 }
 
 // LoginUser проверяет существование пользователя и правильность пароля.
@@ -114,10 +126,87 @@ func (a *UserUsecaseHandler) GetUserIdFromSession(sessionID string) (string, err
 	return userID, nil
 }
 
-func (a *UserUsecaseHandler) AddLose(userID string) error {
-	err := a.userStorage.AddLose(context.Background(), userID)
+// RecordGameResults учитывает итог партии в статистике её игроков.
+func (a *UserUsecaseHandler) RecordGameResults(ctx context.Context, gameKey string, outcomes []user.GameOutcome) error {
+	return a.userStorage.RecordGameResults(ctx, gameKey, outcomes)
+}
+
+// ReplaceStatistic сохраняет статистику, пересчитанную по партиям gameKeys.
+func (a *UserUsecaseHandler) ReplaceStatistic(ctx context.Context, userID string, statistic user.UserStatistic, gameKeys []string) error {
+	return a.userStorage.ReplaceStatistic(ctx, userID, statistic, gameKeys)
+}
+
+// ApplyRatedGame пересчитывает рейтинги обоих игроков по итогу рейтинговой партии.
+// Оба новых рейтинга считаются от рейтингов до партии. Повторный вызов после частичного
+// сбоя берёт уже сохранённые записи истории, поэтому доучитывает только пропущенного игрока.
+func (a *UserUsecaseHandler) ApplyRatedGame(ctx context.Context, gameKey string, outcomes []user.GameOutcome) error {
+	if len(outcomes) != 2 {
+		return nil
+	}
+	entries, err := a.userStorage.GetGameRatingEntries(ctx, gameKey)
 	if err != nil {
 		return err
 	}
-	return nil
+	if len(entries) != len(outcomes) {
+		if entries, err = a.rateGame(ctx, gameKey, outcomes); err != nil {
+			return err
+		}
+	}
+	return a.userStorage.ApplyRatings(ctx, gameKey, entries)
+}
+
+// rateGame считает новые рейтинги игроков партии от их текущих рейтингов.
+func (a *UserUsecaseHandler) rateGame(ctx context.Context, gameKey string, outcomes []user.GameOutcome) ([]rating.HistoryEntry, error) {
+	before := make([]rating.Rating, len(outcomes))
+	for i, outcome := range outcomes {
+		player, err := a.userStorage.GetUserByID(ctx, outcome.UserID)
+		if err != nil {
+			return nil, err
+		}
+		before[i] = player.Glicko()
+	}
+
+	now := time.Now()
+	entries := make([]rating.HistoryEntry, 0, len(outcomes))
+	for i, outcome := range outcomes {
+		opponent := 1 - i
+		score := outcomeScore(outcome.Outcome)
+		entries = append(entries, rating.HistoryEntry{
+			UserID:     outcome.UserID,
+			GameKey:    gameKey,
+			OpponentID: outcomes[opponent].UserID,
+			Score:      score,
+			Before:     before[i],
+			After:      rating.Update(before[i], []rating.Result{{Opponent: before[opponent], Score: score}}),
+			CreatedAt:  now,
+		})
+	}
+	return entries, nil
+}
+
+func outcomeScore(outcome string) float64 {
+	switch outcome {
+	case user.OutcomeWin:
+		return rating.ScoreWin
+	case user.OutcomeDraw:
+		return rating.ScoreDraw
+	}
+	return rating.ScoreLoss
+}
+
+// GetRatingHistory возвращает текущий рейтинг пользователя и его изменения по партиям.
+func (a *UserUsecaseHandler) GetRatingHistory(ctx context.Context, userID string) (rating.HistoryResponse, error) {
+	player, err := a.userStorage.GetUserByID(ctx, userID)
+	if err != nil {
+		return rating.HistoryResponse{}, err
+	}
+	history, err := a.userStorage.GetRatingHistory(ctx, userID)
+	if err != nil {
+		return rating.HistoryResponse{}, err
+	}
+	return rating.HistoryResponse{
+		UserID:  userID,
+		Current: player.Glicko(),
+		History: history,
+	}, nil
 }
@@ -3,10 +3,19 @@ package game
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
-	"strings"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/bot"
+	"team_exe/internal/domain/chat"
+	"team_exe/internal/domain/clock"
+	"team_exe/internal/domain/coord"
 	"team_exe/internal/domain/game"
+	"team_exe/internal/domain/rank"
+	"team_exe/internal/domain/rules"
 	sgf "team_exe/internal/domain/sgf"
+	"team_exe/internal/domain/user"
 	"team_exe/internal/errors"
 	"team_exe/internal/statuses"
 	"team_exe/internal/usecase/auth"
@@ -16,6 +25,7 @@ import (
 type GameStore interface {
 	GenerateGameKeys(ctx context.Context) (gameKeySecret string, gameKeyPublic string)
 	PutGameToMongoDatabase(ctx context.Context, gameData game.Game) bool
+	DeleteGame(ctx context.Context, gameKey string) error
 	AddPlayer(ctx context.Context, userId string, gameKey string) (game.Game, bool)
 	GetGameByGameKey(ctx context.Context, gameKey string) game.Game
 	SaveSGFToRedis(key string, sgfText string) error
@@ -24,6 +34,15 @@ type GameStore interface {
 	GetGameByPublicKey(ctx context.Context, gameKeyPublic string) (game.Game, error)
 	GetActiveGameByUserId(ctx context.Context, userID string) (game.Game, error)
 	LeaveGameBySecretKey(ctx context.Context, secretKey string, userID string) error
+	AppendMove(ctx context.Context, gameKey string, move game.Move, whoIsNext string, clockState *clock.State) error
+	AppendChatMessage(ctx context.Context, gameKey string, message chat.Message) error
+	UpdateGameStatus(ctx context.Context, gameKey string, status string) error
+	SaveScoring(ctx context.Context, gameKey string, deadStones []string, scoreAccepted map[string]bool) error
+	FinishGame(ctx context.Context, gameKey string, result game.Result, sgfText string) error
+	SetHandicapStones(ctx context.Context, gameKey string, stones []string, whoIsNext string) error
+	ApplyUndo(ctx context.Context, gameKey string, moves []game.Move, whoIsNext string, undosUsed map[string]int) error
+	GetAllActiveGames(ctx context.Context) ([]game.Game, error)
+	GetCompletedGamesByUserId(ctx context.Context, userID string) ([]game.Game, error)
 
 	GetArchiveGamesByYear(ctx context.Context, year int, pageNum int) (*game.ArchiveResponse, error)
 	GetArchiveYears(ctx context.Context) (*game.ArchiveYearsResponse, error)
@@ -32,40 +51,198 @@ type GameStore interface {
 	GetGameFromArchiveById(ctx context.Context, gameFromArchiveById string) (*game.GameFromArchive, error)
 }
 
+// Лимит сообщений чата одного пользователя.
+const (
+	chatRateLimit    = 5
+	chatRateInterval = 10 * time.Second
+)
+
 type GameUseCase struct {
 	store       GameStore
 	userUsecase *auth.UserUsecaseHandler
+	chatFilter  *chat.Filter
+	chatLimiter *chat.RateLimiter
 }
 
 func NewGameUseCase(store GameStore, auth *auth.UserUsecaseHandler) *GameUseCase {
-	return &GameUseCase{store: store, userUsecase: auth}
+	return &GameUseCase{
+		store:       store,
+		userUsecase: auth,
+		chatFilter:  chat.NewFilter(nil),
+		chatLimiter: chat.NewRateLimiter(chatRateLimit, chatRateInterval),
+	}
 }
 
 func (g *GameUseCase) CreateGame(ctx context.Context, newGameRequest game.CreateGameRequest, creatorID string) (err error, gameKeyPublic string, gameKeySecret string) {
-	gameKeySecret, gameKeyPublic = g.store.GenerateGameKeys(ctx)
+	newGame, err := g.newGame(ctx, newGameRequest, creatorID)
+	if err != nil {
+		return err, "", ""
+	}
+
+	// getUserById - TODO добавить его в срез Users
+
+	ok := g.store.PutGameToMongoDatabase(ctx, newGame)
+	if !ok {
+		return errors.ErrCreateGameFailed, "", ""
+	}
+	return nil, newGame.GameKeyPublic, newGame.GameKeySecret
+}
+
+// DeleteGame удаляет партию, которую так и не начали, например когда подбор соперника
+// не смог довести её создание до конца.
+func (g *GameUseCase) DeleteGame(ctx context.Context, gameKeySecret string) error {
+	return g.store.DeleteGame(ctx, gameKeySecret)
+}
+
+// newGame проверяет параметры новой партии и собирает её, не сохраняя.
+func (g *GameUseCase) newGame(ctx context.Context, newGameRequest game.CreateGameRequest, creatorID string) (game.Game, error) {
+	ruleset, err := rules.Parse(newGameRequest.Rules)
+	if err != nil {
+		return game.Game{}, err
+	}
+
+	handicapType, handicapStones, err := handicapSetup(newGameRequest)
+	if err != nil {
+		return game.Game{}, err
+	}
+
+	if err = newGameRequest.TimeControl.Validate(); err != nil {
+		return game.Game{}, err
+	}
+
+	if newGameRequest.MaxSpectators < 0 {
+		return game.Game{}, errors.ErrBadSpectatorLimit
+	}
+
+	komi := newGameRequest.Komi
+	if komi == 0 {
+		komi = ruleset.DefaultKomi(newGameRequest.Handicap)
+	}
+
+	gameKeySecret, gameKeyPublic := g.store.GenerateGameKeys(ctx)
 
 	newGame := game.Game{
-		BoardSize:     newGameRequest.BoardSize,
-		Komi:          newGameRequest.Komi,
-		GameKeySecret: gameKeySecret,
-		GameKeyPublic: gameKeyPublic,
-		Status:        statuses.StatusWaitOpponent,
-		CreatedAt:     time.Now(),
+		BoardSize:      newGameRequest.BoardSize,
+		Komi:           komi,
+		Rules:          ruleset.Name,
+		Handicap:       newGameRequest.Handicap,
+		HandicapType:   handicapType,
+		HandicapStones: handicapStones,
+		TimeControl:    newGameRequest.TimeControl,
+		Moves:          []game.Move{}, // ходы дописываются в документ через $push
+		GameKeySecret:  gameKeySecret,
+		GameKeyPublic:  gameKeyPublic,
+		Status:         statuses.StatusWaitOpponent,
+		CreatedAt:      time.Now(),
+		WhoIsNext:      board.Black.String(),
+	}
+	if newGame.Handicap >= 2 && !handicapPending(newGame) {
+		newGame.WhoIsNext = board.White.String()
 	}
 
+	// в рейтинговых играх ходы не отменяются
+	newGame.Rated = newGameRequest.Rated
+	newGame.AllowUndo = newGameRequest.AllowUndo && !newGameRequest.Rated
+	newGame.UndoLimit = newGameRequest.UndoLimit
+
+	newGame.IsPublic = newGameRequest.IsPublic
+	newGame.MaxSpectators = newGameRequest.MaxSpectators
+
 	if newGameRequest.IsCreatorBlack {
 		newGame.PlayerBlack = creatorID
 	} else {
 		newGame.PlayerWhite = creatorID
 	}
 
-	// getUserById - TODO добавить его в срез Users
+	return newGame, nil
+}
 
-	ok := g.store.PutGameToMongoDatabase(ctx, newGame)
-	if !ok {
-		return errors.ErrCreateGameFailed, "", ""
+// CreateBotGame создаёт партию пользователя против KataGo на выбранном уровне силы. Бот сразу
+// занимает своё место, поэтому партия начинается, как только пользователь подключится к ней.
+// Камни форы ставятся фиксированной расстановкой: свободную расстановку бот не делает.
+func (g *GameUseCase) CreateBotGame(ctx context.Context, req game.CreateBotGameRequest, userID string) (string, error) {
+	botColor := board.White
+	if req.BotColor != "" {
+		color, err := board.ParseColor(req.BotColor)
+		if err != nil {
+			return "", errors.ErrBadBotColor
+		}
+		botColor = color
+	}
+	if req.BoardSize == 0 {
+		req.BoardSize = coord.DefaultBoardSize
 	}
-	return nil, gameKeyPublic, gameKeySecret
+	if req.BoardSize < 2 || req.BoardSize > coord.DefaultBoardSize {
+		return "", errors.ErrBotBoardSize
+	}
+	strength, err := bot.ParseLevel(req.Level)
+	if err != nil {
+		return "", err
+	}
+
+	play, err := g.newGame(ctx, game.CreateGameRequest{
+		BoardSize:      req.BoardSize,
+		Komi:           req.Komi,
+		IsCreatorBlack: botColor == board.White,
+		Rules:          req.Rules,
+		Handicap:       req.Handicap,
+		HandicapType:   game.HandicapFixed,
+		IsPublic:       req.IsPublic,
+	}, userID)
+	if err != nil {
+		return "", err
+	}
+	if botColor == board.White {
+		play.PlayerWhite = game.BotPlayerID
+	} else {
+		play.PlayerBlack = game.BotPlayerID
+	}
+	play.BotLevel = strength.HumanRank
+
+	if !g.store.PutGameToMongoDatabase(ctx, play) {
+		return "", errors.ErrCreateGameFailed
+	}
+	minSGF := g.PrepareSgfFile(play)
+	if err = g.store.SaveSGFToRedis(play.GameKeySecret, sgf.Serialize(&minSGF)); err != nil {
+		return "", err
+	}
+	return play.GameKeyPublic, nil
+}
+
+// handicapSetup проверяет параметры форы и для фиксированной расстановки возвращает камни форы.
+func handicapSetup(req game.CreateGameRequest) (string, []string, error) {
+	handicapType := req.HandicapType
+	if handicapType == "" {
+		handicapType = game.HandicapFixed
+	}
+	if handicapType != game.HandicapFixed && handicapType != game.HandicapFree {
+		return "", nil, errors.ErrUnknownHandicapType
+	}
+	if req.Handicap < 0 || req.Handicap > board.MaxHandicap {
+		return "", nil, board.ErrBadHandicap
+	}
+	// фора в один камень означает лишь то, что чёрные ходят первыми с минимальным коми
+	if req.Handicap < 2 {
+		return handicapType, nil, nil
+	}
+
+	points, err := board.FixedHandicap(req.BoardSize, req.Handicap)
+	if err != nil {
+		return "", nil, err
+	}
+	if handicapType == game.HandicapFree {
+		return handicapType, nil, nil
+	}
+	stones := make([]string, 0, len(points))
+	for _, p := range points {
+		stones = append(stones, p.SGF())
+	}
+	return handicapType, stones, nil
+}
+
+// handicapPending сообщает, что чёрные ещё не закончили свободную расстановку форы.
+func handicapPending(play game.Game) bool {
+	return play.Handicap >= 2 && len(play.HandicapStones) < play.Handicap
 }
 
 func (g *GameUseCase) JoinGame(ctx context.Context, play game.Game, userID string) (game game.Game, err error) {
@@ -75,7 +252,7 @@ func (g *GameUseCase) JoinGame(ctx context.Context, play game.Game, userID strin
 	}
 
 	minSGF := g.PrepareSgfFile(updatedGame)
-	sgfString := SerializeSGF(&minSGF)
+	sgfString := sgf.Serialize(&minSGF)
 	err = g.store.SaveSGFToRedis(updatedGame.GameKeySecret, sgfString)
 	if err != nil {
 		return game, err
@@ -84,28 +261,24 @@ func (g *GameUseCase) JoinGame(ctx context.Context, play game.Game, userID strin
 	return updatedGame, nil
 }
 
+// LeaveGame выводит пользователя из его активной партии. Пока соперника нет, пользователь
+// просто освобождает место, а начатую партию он покидает сдачей.
 func (g *GameUseCase) LeaveGame(ctx context.Context, gamePublicKey, userID string) (bool, error) {
 	play, err := g.store.GetActiveGameByUserId(ctx, userID)
 	if err != nil {
 		return false, err
 	}
-	if (play.PlayerWhite == "" && play.PlayerBlack != "") || (play.PlayerWhite != "" && play.PlayerBlack == "") {
+	if play.PlayerWhite == "" || play.PlayerBlack == "" {
 		// пользователь один, значит просто выходит
 		err = g.store.LeaveGameBySecretKey(ctx, play.GameKeySecret, userID)
 		if err != nil {
 			return false, err
 		}
-
 		return true, nil
-	} else if play.PlayerWhite != "" && play.PlayerBlack != "" {
-		err := g.userUsecase.AddLose(userID)
-		if err != nil {
-			return false, err
-		}
-		err = g.store.LeaveGameBySecretKey(ctx, play.GameKeySecret, userID)
-		if err != nil {
-			return false, err
-		}
+	}
+
+	if _, err = g.Resign(ctx, &play, userID); err != nil && play.Status != statuses.StatusCompleted {
+		return false, err
 	}
 	return true, nil
 }
@@ -120,32 +293,123 @@ func (g *GameUseCase) GetGameByPublicKey(ctx context.Context, gameKeyPublic stri
 		return game.Game{}, fmt.Errorf("игры с ключом %s не найдено", gameKeyPublic)
 	}
 
-	sgfStringOfGame, err := g.GetSgfStringByGameKey(play.GameKeySecret)
-	if err != nil {
-		// а вот ничего не будет ахахах
-	}
-
-	play.Sgf = sgfStringOfGame
+	play.Sgf = g.liveSgf(&play)
 
 	return play, nil
 }
 
-func (g *GameUseCase) GetGameInfoByPublicKey(ctx context.Context, gameKeyPublic string) (game.Game, error) {
+// GetGameInfoByPublicKey возвращает игру вместе с никами, рейтингами и рангами игроков.
+func (g *GameUseCase) GetGameInfoByPublicKey(ctx context.Context, gameKeyPublic string) (game.GetGameInfoResponse, error) {
 	play, err := g.store.GetGameByPublicKey(ctx, gameKeyPublic)
 	if err != nil {
-		return game.Game{}, err
+		return game.GetGameInfoResponse{}, err
 	}
 
 	if play.GameKeySecret == "" {
-		return game.Game{}, fmt.Errorf("игры с ключом %s не найдено", gameKeyPublic)
+		return game.GetGameInfoResponse{}, fmt.Errorf("игры с ключом %s не найдено", gameKeyPublic)
 	}
-	sgfStringOfGame, _ := g.GetSgfStringByGameKey(play.GameKeySecret)
+	play.Sgf = g.liveSgf(&play)
 
-	play.Sgf = sgfStringOfGame
+	info := game.GetGameInfoResponse{Game: play}
+	info.Chat = chat.Visible(play.Chat, play.Status == statuses.StatusCompleted)
+	if play.PlayerBlack == game.BotPlayerID {
+		info.PlayerBlackNickname = game.BotNickname
+	} else if play.PlayerBlack != "" {
+		if player, err := g.userUsecase.GetUserByUserId(ctx, play.PlayerBlack); err == nil {
+			info.PlayerBlackNickname = player.Username
+			info.PlayerBlackRating = player.Glicko().Rating
+			info.PlayerBlackRank = player.Rank
+		}
+	}
+	if play.PlayerWhite == game.BotPlayerID {
+		info.PlayerWhiteNickname = game.BotNickname
+	} else if play.PlayerWhite != "" {
+		if player, err := g.userUsecase.GetUserByUserId(ctx, play.PlayerWhite); err == nil {
+			info.PlayerWhiteNickname = player.Username
+			info.PlayerWhiteRating = player.Glicko().Rating
+			info.PlayerWhiteRank = player.Rank
+		}
+	}
+	return info, nil
+}
 
-	//playerBlackNickname :=
+// CheckSpectator проверяет, что к партии, за которой уже наблюдают spectators зрителей,
+// может подключиться ещё один: она открыта для зрителей и их предел ещё не достигнут.
+func (g *GameUseCase) CheckSpectator(play *game.Game, spectators int) error {
+	if !play.IsPublic {
+		return errors.ErrGameNotPublic
+	}
+	if play.MaxSpectators > 0 && spectators >= play.MaxSpectators {
+		return errors.ErrSpectatorLimit
+	}
+	return nil
+}
 
-	return play, nil
+// Snapshot собирает полное состояние партии: позицию, ходы, SGF, часы и пленных.
+// Игроку до конца партии не показывается канал зрителей. Подключение игроков,
+// число зрителей и номер события заполняет слой доставки.
+func (g *GameUseCase) Snapshot(play *game.Game, forPlayer bool, now time.Time) game.GameSnapshot {
+	snapshot := game.GameSnapshot{
+		Event:       game.EventSnapshot,
+		Status:      play.Status,
+		BoardSize:   play.BoardSize,
+		Komi:        play.Komi,
+		Rules:       play.Rules,
+		PlayerBlack: play.PlayerBlack,
+		PlayerWhite: play.PlayerWhite,
+		Moves:       play.Moves,
+		WhoIsNext:   play.WhoIsNext,
+		Chat:        play.Chat,
+	}
+	if forPlayer {
+		snapshot.Chat = chat.Visible(play.Chat, play.Status == statuses.StatusCompleted)
+	}
+	snapshot.SGF = g.liveSgf(play)
+	if play.Result != nil {
+		snapshot.Result = play.Result.SgfString()
+	}
+	if play.Clock != nil {
+		state := play.Clock.State(now)
+		snapshot.Clock = &state
+	}
+	if play.Board != nil {
+		snapshot.Board = play.Board.Rows()
+		snapshot.Captures = map[string]int{
+			board.Black.String(): play.Board.Captures(board.Black),
+			board.White.String(): play.Board.Captures(board.White),
+		}
+	}
+	return snapshot
+}
+
+// SuggestHandicap предлагает фору и коми для партии создателя с соперником по разнице их рангов.
+// Более слабый игрок получает чёрные.
+func (g *GameUseCase) SuggestHandicap(ctx context.Context, creatorID string, req game.HandicapSuggestionRequest) (game.HandicapSuggestion, error) {
+	ruleset, err := rules.Parse(req.Rules)
+	if err != nil {
+		return game.HandicapSuggestion{}, err
+	}
+	creator, err := g.userUsecase.GetUserByUserId(ctx, creatorID)
+	if err != nil {
+		return game.HandicapSuggestion{}, err
+	}
+	opponent, err := g.userUsecase.GetUserByUserId(ctx, req.OpponentID)
+	if err != nil {
+		return game.HandicapSuggestion{}, err
+	}
+
+	suggestion := game.HandicapSuggestion{
+		CreatorRank:    *creator.Rank,
+		OpponentRank:   *opponent.Rank,
+		IsCreatorBlack: creator.Rank.Compare(*opponent.Rank) <= 0,
+	}
+	stronger, weaker := *opponent.Rank, *creator.Rank
+	if !suggestion.IsCreatorBlack {
+		stronger, weaker = weaker, stronger
+	}
+	suggestion.Handicap = min(max(rank.Handicap(stronger, weaker, req.BoardSize), 0), board.MaxHandicap)
+	suggestion.Komi = ruleset.DefaultKomi(suggestion.Handicap)
+	return suggestion, nil
 }
 
 func (g *GameUseCase) GetGameBySecreteKey(ctx context.Context, gameUniqueKey string) (game.Game, error) {
@@ -159,6 +423,10 @@ func (g *GameUseCase) GetGameBySecreteKey(ctx context.Context, gameUniqueKey str
 }
 
 func (g *GameUseCase) PrepareSgfFile(gameData game.Game) sgf.SGF {
+	ruleset, err := rules.Parse(gameData.Rules)
+	if err != nil {
+		ruleset = rules.Default
+	}
 	minSGF := sgf.SGF{
 		Root: &sgf.GameTree{
 			Nodes: []sgf.Node{
@@ -172,24 +440,41 @@ func (g *GameUseCase) PrepareSgfFile(gameData game.Game) sgf.SGF {
 						"DT": {gameData.CreatedAt.String()},
 						"RE": {""},
 						"KM": {strconv.FormatFloat(gameData.Komi, 'f', 1, 64)},
-						"RU": {"Chinese"},
+						"RU": {ruleset.SgfName},
 						"C":  {"Game 1 x 1"},
 					},
 				},
 			},
 		},
 	}
+	if gameData.Handicap >= 2 {
+		root := minSGF.Root.Nodes[0].Properties
+		root["HA"] = []string{strconv.Itoa(gameData.Handicap)}
+		if len(gameData.HandicapStones) > 0 {
+			root["AB"] = gameData.HandicapStones
+		}
+		if !handicapPending(gameData) {
+			root["PL"] = []string{board.White.String()}
+		}
+	}
+	if tc := gameData.TimeControl; tc.Enabled() {
+		root := minSGF.Root.Nodes[0].Properties
+		root["TM"] = []string{strconv.Itoa(tc.MainTime)}
+		if ot := tc.Overtime(); ot != "" {
+			root["OT"] = []string{ot}
+		}
+	}
 	return minSGF
 }
 
-func AddMovesToSgf(tree *sgf.GameTree, moves []game.Move) {
+func AddMovesToSgf(tree *sgf.SGF, moves []game.Move) {
 	for _, move := range moves {
 		node := sgf.Node{
 			Properties: map[string][]string{
 				move.Color: {move.Coordinates},
 			},
 		}
-		tree.Nodes = append(tree.Nodes, node)
+		tree.AppendNode(node)
 	}
 }
 
@@ -197,44 +482,725 @@ func (g *GameUseCase) GetSgfStringByGameKey(key string) (string, error) {
 	return g.store.LoadSGFFromRedis(key)
 }
 
-func SerializeSGF(s *sgf.SGF) string {
-	var builder strings.Builder
-	builder.WriteString("(")
-	serializeGameTree(&builder, s.Root)
-	builder.WriteString(")")
-	return builder.String()
+// liveSgf возвращает SGF партии из Redis, где он обновляется с каждым ходом.
+// Ключ в Redis живёт ограниченное время, поэтому, если его уже нет, возвращается SGF из MongoDB,
+// сохранённый при завершении партии.
+func (g *GameUseCase) liveSgf(play *game.Game) string {
+	if sgfText, err := g.GetSgfStringByGameKey(play.GameKeySecret); err == nil && sgfText != "" {
+		return sgfText
+	}
+	return play.Sgf
+}
+
+// PlayMove проверяет ход игрока по правилам и применяет его к позиции игры.
+// Недопустимый ход не попадает ни в SGF, ни в список ходов.
+func (g *GameUseCase) PlayMove(ctx context.Context, play *game.Game, playerID string, move game.Move) (game.Move, string, error) {
+	color, err := g.prepareTurn(play, playerID)
+	if err != nil {
+		return game.Move{}, "", err
+	}
+	if move.Color != "" {
+		moveColor, err := board.ParseColor(move.Color)
+		if err != nil {
+			return game.Move{}, "", err
+		}
+		if moveColor != color {
+			return game.Move{}, "", errors.ErrNotYourColor
+		}
+	}
+
+	point, err := coord.Parse(move.Coordinates, play.BoardSize)
+	if err != nil {
+		return game.Move{}, "", err
+	}
+	if point.Pass {
+		return g.Pass(ctx, play, playerID)
+	}
+
+	if handicapPending(*play) {
+		return g.placeHandicapStone(ctx, play, color, point)
+	}
+
+	if _, err = play.Board.Play(color, point); err != nil {
+		return game.Move{}, "", err
+	}
+
+	return g.recordMove(ctx, play, game.Move{Color: color.String(), Coordinates: point.SGF()})
 }
 
-func serializeGameTree(builder *strings.Builder, tree *sgf.GameTree) {
-	for _, node := range tree.Nodes {
-		builder.WriteString(";")
+// placeHandicapStone ставит очередной камень свободной форы. Камни форы записываются
+// в корневой узел SGF, а после последнего из них очередь хода переходит к белым.
+func (g *GameUseCase) placeHandicapStone(ctx context.Context, play *game.Game, color board.Color, point coord.Point) (game.Move, string, error) {
+	if color != board.Black {
+		return game.Move{}, "", board.ErrWrongTurn
+	}
+	// до окончания расстановки ходов нет, поэтому SGF состоит из одного корневого узла
+	previousSGF := g.PrepareSgfFile(*play)
+	placed := *play
+	placed.HandicapStones = append(slices.Clone(play.HandicapStones), point.SGF())
+	whoIsNext := board.Black
+	if !handicapPending(placed) {
+		whoIsNext = board.White
+	}
+	minSGF := g.PrepareSgfFile(placed)
+	sgfString := sgf.Serialize(&minSGF)
+
+	if err := play.Board.Setup(board.Black, point); err != nil {
+		return game.Move{}, "", err
+	}
+	err := g.saveSgf(play.GameKeySecret, sgf.Serialize(&previousSGF), sgfString, func() error {
+		return g.store.SetHandicapStones(ctx, play.GameKeySecret, placed.HandicapStones, whoIsNext.String())
+	})
+	if err != nil {
+		// камень уже стоит на доске, поэтому позиция будет восстановлена из сохранённой форы
+		play.Board = nil
+		return game.Move{}, "", err
+	}
 
-		// фиксированный порядок свойств SGF
-		orderedKeys := []string{"FF", "GM", "SZ", "PB", "PW", "DT", "RE", "KM", "RU", "C", "B", "W"}
-		used := make(map[string]bool)
-		for _, key := range orderedKeys {
-			if values, ok := node.Properties[key]; ok {
-				used[key] = true
-				for _, v := range values {
-					builder.WriteString(fmt.Sprintf("%s[%s]", key, v))
-				}
-			}
+	play.HandicapStones = placed.HandicapStones
+	play.Board.SetToPlay(whoIsNext)
+	play.WhoIsNext = whoIsNext.String()
+	switchClock(play)
+
+	return game.Move{Color: color.String(), Coordinates: point.SGF()}, sgfString, nil
+}
+
+// Pass записывает пас игрока. После двух пасов подряд партия переходит в фазу подсчёта.
+func (g *GameUseCase) Pass(ctx context.Context, play *game.Game, playerID string) (game.Move, string, error) {
+	color, err := g.prepareTurn(play, playerID)
+	if err != nil {
+		return game.Move{}, "", err
+	}
+
+	if handicapPending(*play) {
+		return game.Move{}, "", errors.ErrHandicapPending
+	}
+
+	if err = play.Board.Pass(color); err != nil {
+		return game.Move{}, "", err
+	}
+
+	accepted, sgfString, err := g.recordMove(ctx, play, game.Move{Color: color.String()})
+	if err != nil {
+		return game.Move{}, "", err
+	}
+
+	if play.Board.ConsecutivePasses() >= 2 {
+		if err = g.store.UpdateGameStatus(ctx, play.GameKeySecret, statuses.StatusScoring); err != nil {
+			return game.Move{}, "", err
+		}
+		// на время подсчёта часы останавливаются
+		if play.Clock != nil {
+			play.Clock.Stop(time.Now())
+		}
+		play.Status = statuses.StatusScoring
+	}
+	return accepted, sgfString, nil
+}
+
+// Resign завершает партию сдачей игрока, победа присуждается сопернику.
+func (g *GameUseCase) Resign(ctx context.Context, play *game.Game, playerID string) (string, error) {
+	color, err := g.prepareAction(play, playerID)
+	if err != nil {
+		return "", err
+	}
+	result := game.Result{
+		WinColor: color.Opponent().String(),
+		Reason:   game.ResultReasonResign,
+	}
+	return g.FinishGame(ctx, play, result)
+}
+
+// RequestUndo сохраняет запрос игрока на отмену его последнего хода до ответа соперника.
+func (g *GameUseCase) RequestUndo(play *game.Game, playerID string) error {
+	color, err := g.prepareTurn(play, playerID)
+	if err != nil {
+		return err
+	}
+	if !play.AllowUndo || play.Rated {
+		return errors.ErrUndoDisabled
+	}
+	if play.UndoLimit > 0 && play.UndosUsed[color.String()] >= play.UndoLimit {
+		return errors.ErrUndoLimit
+	}
+	if undoPlies(play.Moves, color) == 0 {
+		return errors.ErrNothingToUndo
+	}
+	play.UndoRequest = &game.UndoRequest{Color: color.String()}
+	return nil
+}
+
+// AnswerUndo принимает или отклоняет запрос соперника на отмену хода. При согласии
+// позиция, список ходов и SGF откатываются до последнего хода попросившего игрока.
+func (g *GameUseCase) AnswerUndo(ctx context.Context, play *game.Game, playerID string, accept bool) (string, error) {
+	color, err := g.prepareTurn(play, playerID)
+	if err != nil {
+		return "", err
+	}
+	request := play.UndoRequest
+	if request == nil || request.Color == color.String() {
+		return "", errors.ErrNoUndoRequest
+	}
+	play.UndoRequest = nil
+	if !accept {
+		return "", nil
+	}
+
+	// пока запрос ждал ответа, соперник мог сделать ход, поэтому число полуходов считается сейчас
+	plies := undoPlies(play.Moves, color.Opponent())
+	if plies == 0 {
+		return "", errors.ErrNothingToUndo
+	}
+
+	previous, err := g.GetSgfStringByGameKey(play.GameKeySecret)
+	if err != nil {
+		return "", err
+	}
+	tree, err := sgf.Parse(previous)
+	if err != nil {
+		return "", err
+	}
+	tree.RemoveLastNodes(plies)
+	sgfString := sgf.Serialize(tree)
+
+	moves := play.Moves[:len(play.Moves)-plies]
+	restored := *play
+	restored.Moves = moves
+	position, err := RestoreBoard(restored)
+	if err != nil {
+		return "", err
+	}
+	undosUsed := maps.Clone(play.UndosUsed)
+	if undosUsed == nil {
+		undosUsed = make(map[string]int)
+	}
+	undosUsed[request.Color]++
+	whoIsNext := position.ToPlay().String()
+	err = g.saveSgf(play.GameKeySecret, previous, sgfString, func() error {
+		return g.store.ApplyUndo(ctx, play.GameKeySecret, moves, whoIsNext, undosUsed)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	play.Moves = moves
+	play.Board = position
+	play.WhoIsNext = whoIsNext
+	play.UndosUsed = undosUsed
+	switchClock(play)
+	return sgfString, nil
+}
+
+// PostChat проверяет, фильтрует и сохраняет сообщение чата. Игроки пишут в канал players,
+// зрители - только в kibitz. Игрокам канал зрителей открыт лишь после завершения партии.
+func (g *GameUseCase) PostChat(ctx context.Context, play *game.Game, userID, channel, text string) (chat.Message, error) {
+	text, err := chat.Normalize(channel, text)
+	if err != nil {
+		return chat.Message{}, err
+	}
+
+	isPlayer := play.PlayerBlack == userID || play.PlayerWhite == userID
+	switch {
+	case channel == chat.ChannelPlayers && !isPlayer:
+		return chat.Message{}, errors.ErrNotAPlayer
+	case channel == chat.ChannelKibitz && isPlayer && play.Status != statuses.StatusCompleted:
+		return chat.Message{}, errors.ErrKibitzForPlayers
+	}
+
+	now := time.Now()
+	if !g.chatLimiter.Allow(userID, now) {
+		return chat.Message{}, chat.ErrRateLimited
+	}
+
+	message := chat.Message{
+		Channel: channel,
+		UserID:  userID,
+		Text:    g.chatFilter.Clean(text),
+		SentAt:  now,
+	}
+	if err = g.store.AppendChatMessage(ctx, play.GameKeySecret, message); err != nil {
+		return chat.Message{}, err
+	}
+	play.Chat = append(play.Chat, message)
+	return message, nil
+}
+
+// undoPlies возвращает, сколько полуходов нужно отменить, чтобы вернуть ход игроку цвета color:
+// один, если он ходил последним, два, если после него успел сходить соперник.
+func undoPlies(moves []game.Move, color board.Color) int {
+	n := len(moves)
+	switch {
+	case n >= 1 && moves[n-1].Color == color.String():
+		return 1
+	case n >= 2 && moves[n-2].Color == color.String():
+		return 2
+	}
+	return 0
+}
+
+// ToggleDeadStones помечает группу камней мёртвой или живой в фазе подсчёта.
+// Любое изменение пометок отменяет ранее данное игроками согласие.
+func (g *GameUseCase) ToggleDeadStones(ctx context.Context, play *game.Game, playerID string, coordinates string) error {
+	if _, err := g.prepareScoring(play, playerID); err != nil {
+		return err
+	}
+	point, err := coord.Parse(coordinates, play.BoardSize)
+	if err != nil {
+		return err
+	}
+	if err = play.Board.ToggleDead(point); err != nil {
+		return err
+	}
+	deadStones := sgfPoints(play.Board.DeadStones())
+	if err = g.store.SaveScoring(ctx, play.GameKeySecret, deadStones, nil); err != nil {
+		// повторная пометка группы снимает её, так что доска возвращается к сохранённым пометкам
+		_ = play.Board.ToggleDead(point)
+		return err
+	}
+	play.DeadStones = deadStones
+	play.ScoreAccepted = nil
+	return nil
+}
+
+// sgfPoints переводит пункты доски в SGF-нотацию.
+func sgfPoints(points []coord.Point) []string {
+	result := make([]string, 0, len(points))
+	for _, p := range points {
+		result = append(result, p.SGF())
+	}
+	return result
+}
+
+// AcceptScore фиксирует согласие игрока с пометкой мёртвых камней. Когда согласны оба,
+// очки подсчитываются по правилам партии и партия завершается, иначе возвращается nil.
+func (g *GameUseCase) AcceptScore(ctx context.Context, play *game.Game, playerID string) (*board.Score, string, error) {
+	color, err := g.prepareScoring(play, playerID)
+	if err != nil {
+		return nil, "", err
+	}
+	accepted := maps.Clone(play.ScoreAccepted)
+	if accepted == nil {
+		accepted = make(map[string]bool)
+	}
+	accepted[color.String()] = true
+	if !accepted[color.Opponent().String()] {
+		if err = g.store.SaveScoring(ctx, play.GameKeySecret, play.DeadStones, accepted); err != nil {
+			return nil, "", err
 		}
+		play.ScoreAccepted = accepted
+		return nil, "", nil
+	}
+
+	ruleset, err := rules.Parse(play.Rules)
+	if err != nil {
+		return nil, "", err
+	}
+	score := play.Board.Score(ruleset.Scoring, play.Komi)
+	sgfString, err := g.FinishGame(ctx, play, ResultFromScore(score))
+	return &score, sgfString, err
+}
+
+// ResultFromScore определяет победителя и разницу в очках по итогам подсчёта.
+func ResultFromScore(score board.Score) game.Result {
+	result := game.Result{Reason: game.ResultReasonScore}
+	switch {
+	case score.Black > score.White:
+		result.WinColor = board.Black.String()
+		result.PointDiff = score.Black - score.White
+	case score.White > score.Black:
+		result.WinColor = board.White.String()
+		result.PointDiff = score.White - score.Black
+	}
+	return result
+}
 
-		for key, values := range node.Properties {
-			if !used[key] {
-				for _, v := range values {
-					builder.WriteString(fmt.Sprintf("%s[%s]", key, v))
-				}
+// FinishGame завершает партию: записывает результат в SGF и в игру, обновляет статистику игроков.
+// Партия в памяти завершается, только когда результат сохранён.
+func (g *GameUseCase) FinishGame(ctx context.Context, play *game.Game, result game.Result) (string, error) {
+	previous, err := g.GetSgfStringByGameKey(play.GameKeySecret)
+	if err != nil {
+		return "", err
+	}
+	sgfString, err := SetSgfResult(previous, result.SgfString())
+	if err != nil {
+		return "", err
+	}
+	err = g.saveSgf(play.GameKeySecret, previous, sgfString, func() error {
+		return g.store.FinishGame(ctx, play.GameKeySecret, result, sgfString)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if play.Clock != nil {
+		play.Clock.Stop(time.Now())
+	}
+	play.Status = statuses.StatusCompleted
+	play.Result = &result
+	play.Sgf = sgfString
+
+	// аннулированная партия не влияет ни на статистику, ни на рейтинг
+	if result.Annulled() {
+		return sgfString, nil
+	}
+	if err = g.updateStatistics(*play, result); err != nil {
+		return sgfString, err
+	}
+	if play.Rated {
+		outcomes := GameOutcomes(*play, result)
+		err = withRetry(func() error {
+			return g.userUsecase.ApplyRatedGame(context.WithoutCancel(ctx), play.GameKeySecret, outcomes)
+		})
+		if err != nil {
+			return sgfString, err
+		}
+	}
+	return sgfString, nil
+}
+
+// updateStatistics учитывает итог партии в статистике обоих игроков, включая ничьи.
+func (g *GameUseCase) updateStatistics(play game.Game, result game.Result) error {
+	outcomes := GameOutcomes(play, result)
+	return withRetry(func() error {
+		return g.userUsecase.RecordGameResults(context.Background(), play.GameKeySecret, outcomes)
+	})
+}
+
+// Запись итога партии в статистику и рейтинг игроков: сколько раз пробовать и пауза между попытками.
+const (
+	resultAttempts   = 3
+	resultRetryDelay = 500 * time.Millisecond
+)
+
+// withRetry повторяет запись итога партии при ошибке. Запись учитывает партию не более
+// одного раза для каждого игрока, поэтому повтор после частичного сбоя доучитывает
+// только пропущенных игроков.
+func withRetry(record func() error) error {
+	var err error
+	for attempt := 0; attempt < resultAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(resultRetryDelay)
+		}
+		if err = record(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// GameOutcomes возвращает итог партии для каждого из игроков-людей.
+func GameOutcomes(play game.Game, result game.Result) []user.GameOutcome {
+	outcomes := make([]user.GameOutcome, 0, 2)
+	for _, color := range []board.Color{board.Black, board.White} {
+		playerID := play.PlayerBlack
+		if color == board.White {
+			playerID = play.PlayerWhite
+		}
+		if playerID == "" || playerID == game.BotPlayerID {
+			continue
+		}
+		outcome := user.OutcomeDraw
+		switch result.WinColor {
+		case color.String():
+			outcome = user.OutcomeWin
+		case color.Opponent().String():
+			outcome = user.OutcomeLoss
+		}
+		outcomes = append(outcomes, user.GameOutcome{
+			UserID:    playerID,
+			Color:     color.String(),
+			BoardSize: play.BoardSize,
+			Outcome:   outcome,
+		})
+	}
+	return outcomes
+}
+
+// RecomputeStatistics пересчитывает статистику пользователя по всем его завершённым партиям
+// и сохраняет её вместо накопленной.
+func (g *GameUseCase) RecomputeStatistics(ctx context.Context, userID string) (user.UserStatistic, error) {
+	games, err := g.store.GetCompletedGamesByUserId(ctx, userID)
+	if err != nil {
+		return user.UserStatistic{}, err
+	}
+
+	statistic := user.UserStatistic{BySize: make(map[string]user.Record)}
+	gameKeys := make([]string, 0, len(games))
+	for _, play := range games {
+		if play.Result == nil || play.Result.Annulled() {
+			continue
+		}
+		for _, outcome := range GameOutcomes(play, *play.Result) {
+			if outcome.UserID == userID {
+				statistic.Apply(outcome)
 			}
 		}
+		gameKeys = append(gameKeys, play.GameKeySecret)
+	}
+
+	if err = g.userUsecase.ReplaceStatistic(ctx, userID, statistic, gameKeys); err != nil {
+		return user.UserStatistic{}, err
+	}
+	return statistic, nil
+}
+
+// prepareAction проверяет, что партия идёт и пользователь в ней играет, и возвращает его цвет.
+func (g *GameUseCase) prepareAction(play *game.Game, playerID string) (board.Color, error) {
+	if play.PlayerBlack == "" || play.PlayerWhite == "" {
+		return board.Empty, errors.ErrGameNotStarted
+	}
+	if play.Status == statuses.StatusCompleted {
+		return board.Empty, errors.ErrGameNotInPlay
+	}
+
+	color, err := PlayerColor(*play, playerID)
+	if err != nil {
+		return board.Empty, err
+	}
+
+	if play.Board == nil {
+		play.Board, err = RestoreBoard(*play)
+		if err != nil {
+			return board.Empty, err
+		}
 	}
+	return color, nil
+}
+
+// prepareTurn дополнительно к prepareAction проверяет, что на доске ещё можно ходить.
+func (g *GameUseCase) prepareTurn(play *game.Game, playerID string) (board.Color, error) {
+	color, err := g.prepareAction(play, playerID)
+	if err != nil {
+		return board.Empty, err
+	}
+	if play.Status == statuses.StatusScoring {
+		return board.Empty, errors.ErrGameNotInPlay
+	}
+	return color, nil
+}
+
+// prepareScoring дополнительно к prepareAction проверяет, что партия в фазе подсчёта.
+func (g *GameUseCase) prepareScoring(play *game.Game, playerID string) (board.Color, error) {
+	color, err := g.prepareAction(play, playerID)
+	if err != nil {
+		return board.Empty, err
+	}
+	if play.Status != statuses.StatusScoring {
+		return board.Empty, errors.ErrNotScoringPhase
+	}
+	return color, nil
+}
+
+// recordMove сохраняет принятый ход в SGF и в журнал ходов игры и передаёт очередь хода сопернику.
+// Возвращает ход с проставленными номером и временем. Список ходов, очередь хода и часы
+// в памяти меняются, только когда ход сохранён.
+func (g *GameUseCase) recordMove(ctx context.Context, play *game.Game, move game.Move) (game.Move, string, error) {
+	playedAt := time.Now()
+	move.MoveNumber = len(play.Moves) + 1
+	move.PlayedAt = &playedAt
+
+	// часы переключаются на копии, чтобы при ошибке записи остаться прежними
+	whoIsNext := play.Board.ToPlay()
+	clockState := play.ClockState
+	var switched *clock.Clock
+	if play.Clock != nil {
+		next := *play.Clock
+		next.Switch(whoIsNext, playedAt)
+		state := next.State(playedAt)
+		switched, clockState = &next, &state
+	}
+
+	previous, err := g.GetSgfStringByGameKey(play.GameKeySecret)
+	var sgfString string
+	if err == nil {
+		sgfString, err = AppendMoveToSgf(previous, move)
+	}
+	if err == nil {
+		err = g.saveSgf(play.GameKeySecret, previous, sgfString, func() error {
+			return g.store.AppendMove(ctx, play.GameKeySecret, move, whoIsNext.String(), clockState)
+		})
+	}
+	if err != nil {
+		// позиция уже изменена, поэтому при следующем ходе она будет восстановлена из списка ходов
+		play.Board = nil
+		return game.Move{}, "", err
+	}
+
+	play.Moves = append(play.Moves, move)
+	play.WhoIsNext = whoIsNext.String()
+	if switched != nil {
+		play.Clock = switched
+		play.ClockState = clockState
+	}
+	return move, sgfString, nil
+}
 
-	for _, child := range tree.Children {
-		builder.WriteString("(")
-		serializeGameTree(builder, child)
-		builder.WriteString(")")
+// saveSgf записывает новый SGF партии в Redis, а затем сохраняет партию в MongoDB через persist.
+// Если сохранить партию не удалось, в Redis возвращается прежний SGF, чтобы он не расходился с базой.
+func (g *GameUseCase) saveSgf(key, previous, sgfString string, persist func() error) error {
+	if err := g.store.SaveSGFToRedis(key, sgfString); err != nil {
+		return err
 	}
+	if err := persist(); err != nil {
+		if rollbackErr := g.store.SaveSGFToRedis(key, previous); rollbackErr != nil {
+			return fmt.Errorf("%w (SGF в Redis не откатился: %w)", err, rollbackErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// StartClock запускает часы игрока, чей ход, когда за доской оба игрока. Часы,
+// которые уже идут, и партии без контроля времени не затрагиваются.
+func (g *GameUseCase) StartClock(play *game.Game, now time.Time) {
+	if !play.TimeControl.Enabled() || play.Clock != nil {
+		return
+	}
+	if play.PlayerBlack == "" || play.PlayerWhite == "" {
+		return
+	}
+	if play.Status == statuses.StatusCompleted || play.Status == statuses.StatusScoring {
+		return
+	}
+	color, err := board.ParseColor(play.WhoIsNext)
+	if err != nil {
+		color = board.Black
+	}
+	if play.ClockState != nil {
+		play.Clock = clock.Restore(play.TimeControl, *play.ClockState, now)
+		return
+	}
+	play.Clock = clock.New(play.TimeControl)
+	play.Clock.Start(color, now)
+}
+
+// GetUnfinishedGames возвращает идущие партии, которые нужно поднять в памяти после перезапуска.
+func (g *GameUseCase) GetUnfinishedGames(ctx context.Context) ([]game.Game, error) {
+	return g.store.GetAllActiveGames(ctx)
+}
+
+// RestoreLiveGame восстанавливает состояние идущей партии из журнала ходов: позицию
+// с пометками мёртвых камней, часы и, если Redis его потерял, SGF. Согласия с подсчётом
+// загружаются из базы вместе с партией.
+func (g *GameUseCase) RestoreLiveGame(play *game.Game) error {
+	position, err := RestoreBoard(*play)
+	if err != nil {
+		return err
+	}
+	play.Board = position
+	play.WhoIsNext = position.ToPlay().String()
+
+	if _, err = g.GetSgfStringByGameKey(play.GameKeySecret); err != nil {
+		tree := g.PrepareSgfFile(*play)
+		AddMovesToSgf(&tree, play.Moves)
+		if err = g.store.SaveSGFToRedis(play.GameKeySecret, sgf.Serialize(&tree)); err != nil {
+			return err
+		}
+	}
+
+	g.StartClock(play, time.Now())
+	return nil
+}
+
+// CheckFlag завершает партию поражением по времени, если у игрока, чей ход, истекло время.
+// Возвращает true, если партия была завершена.
+func (g *GameUseCase) CheckFlag(ctx context.Context, play *game.Game, now time.Time) (bool, string, error) {
+	if play.Clock == nil || play.Status == statuses.StatusCompleted {
+		return false, "", nil
+	}
+	color, flagged := play.Clock.Flagged(now)
+	if !flagged {
+		return false, "", nil
+	}
+	result := game.Result{
+		WinColor: color.Opponent().String(),
+		Reason:   game.ResultReasonTime,
+	}
+	sgfString, err := g.FinishGame(ctx, play, result)
+	return play.Status == statuses.StatusCompleted, sgfString, err
+}
+
+// Abandon присуждает партию, которую игрок цвета color покинул и не вернулся за отведённое время:
+// победа достаётся оставшемуся сопернику. Если ходов сделано меньше minMoves или соперник тоже
+// отключён, партия аннулируется.
+func (g *GameUseCase) Abandon(ctx context.Context, play *game.Game, color board.Color, opponentOnline bool, minMoves int) (string, error) {
+	if play.Status == statuses.StatusCompleted || play.PlayerBlack == "" || play.PlayerWhite == "" {
+		return "", errors.ErrGameNotInPlay
+	}
+	result := game.Result{Reason: game.ResultReasonAnnulled}
+	if opponentOnline && len(play.Moves) >= minMoves {
+		result = game.Result{
+			WinColor: color.Opponent().String(),
+			Reason:   game.ResultReasonAbandon,
+		}
+	}
+	return g.FinishGame(ctx, play, result)
+}
+
+// switchClock переводит часы на игрока, чей теперь ход.
+func switchClock(play *game.Game) {
+	if play.Clock == nil {
+		return
+	}
+	if next, err := board.ParseColor(play.WhoIsNext); err == nil {
+		play.Clock.Switch(next, time.Now())
+	}
+}
+
+// PlayerColor возвращает цвет, которым играет пользователь.
+func PlayerColor(play game.Game, userID string) (board.Color, error) {
+	switch userID {
+	case play.PlayerBlack:
+		return board.Black, nil
+	case play.PlayerWhite:
+		return board.White, nil
+	}
+	return board.Empty, errors.ErrNotAPlayer
+}
+
+// RestoreBoard восстанавливает позицию, последовательно проигрывая ходы партии,
+// и возвращает на доску сохранённые пометки мёртвых камней.
+func RestoreBoard(play game.Game) (*board.Board, error) {
+	ruleset, err := rules.Parse(play.Rules)
+	if err != nil {
+		return nil, err
+	}
+	b := board.New(play.BoardSize, ruleset.Ko)
+	for _, stone := range play.HandicapStones {
+		point, err := coord.ParseSGF(stone, play.BoardSize)
+		if err != nil {
+			return nil, fmt.Errorf("камень форы %s: %w", stone, err)
+		}
+		if err = b.Setup(board.Black, point); err != nil {
+			return nil, fmt.Errorf("камень форы %s: %w", stone, err)
+		}
+	}
+	if play.Handicap >= 2 && !handicapPending(play) {
+		b.SetToPlay(board.White)
+	}
+	for i, move := range play.Moves {
+		color, err := board.ParseColor(move.Color)
+		if err != nil {
+			return nil, fmt.Errorf("ход %d: %w", i+1, err)
+		}
+		point, err := coord.ParseSGF(move.Coordinates, play.BoardSize)
+		if err != nil {
+			return nil, fmt.Errorf("ход %d: %w", i+1, err)
+		}
+		if _, err = b.Play(color, point); err != nil {
+			return nil, fmt.Errorf("ход %d: %w", i+1, err)
+		}
+	}
+	for _, stone := range play.DeadStones {
+		point, err := coord.ParseSGF(stone, play.BoardSize)
+		if err != nil {
+			return nil, fmt.Errorf("мёртвый камень %s: %w", stone, err)
+		}
+		if err = b.MarkDead(point); err != nil {
+			return nil, fmt.Errorf("мёртвый камень %s: %w", stone, err)
+		}
+	}
+	return b, nil
 }
 
 func (g *GameUseCase) AddMoveToGameSgf(key string, move game.Move) (string, error) {
@@ -242,7 +1208,10 @@ func (g *GameUseCase) AddMoveToGameSgf(key string, move game.Move) (string, erro
 	if err != nil {
 		return "", err
 	}
-	newSgfString := AppendMoveToSgf(sgfString, move)
+	newSgfString, err := AppendMoveToSgf(sgfString, move)
+	if err != nil {
+		return "", err
+	}
 	err = g.store.SaveSGFToRedis(key, newSgfString)
 	if err != nil {
 		return "", err
@@ -250,11 +1219,24 @@ func (g *GameUseCase) AddMoveToGameSgf(key string, move game.Move) (string, erro
 	return newSgfString, nil
 }
 
-func AppendMoveToSgf(sgfText string, move game.Move) string {
-	if strings.HasSuffix(sgfText, ")") {
-		sgfText = sgfText[:len(sgfText)-1]
+// SetSgfResult записывает результат партии в свойство RE корневого узла.
+func SetSgfResult(sgfText string, result string) (string, error) {
+	tree, err := sgf.Parse(sgfText)
+	if err != nil {
+		return "", err
 	}
-	return sgfText + fmt.Sprintf(";%s[%s])", move.Color, move.Coordinates)
+	tree.RootNode().SetProperty("RE", result)
+	return sgf.Serialize(tree), nil
+}
+
+// AppendMoveToSgf добавляет ход в конец основной линии партии.
+func AppendMoveToSgf(sgfText string, move game.Move) (string, error) {
+	tree, err := sgf.Parse(sgfText)
+	if err != nil {
+		return "", err
+	}
+	AddMovesToSgf(tree, []game.Move{move})
+	return sgf.Serialize(tree), nil
 }
 
 func (g *GameUseCase) IsUserInGameByGameId(ctx context.Context, userID string, gameKey string) bool {
@@ -279,6 +1261,7 @@ func (g *GameUseCase) GetArchiveOfGames(ctx context.Context, pageNumber, year in
 		if err != nil {
 			return nil, err
 		}
+		parseArchiveRanks(archiveResp)
 		return archiveResp, nil
 	}
 	if name != "" {
@@ -286,12 +1269,22 @@ func (g *GameUseCase) GetArchiveOfGames(ctx context.Context, pageNumber, year in
 		if err != nil {
 			return nil, err
 		}
+		parseArchiveRanks(archiveResp)
 		return archiveResp, nil
 	}
 
 	return nil, nil
 }
 
+func parseArchiveRanks(resp *game.ArchiveResponse) {
+	if resp == nil {
+		return
+	}
+	for i := range resp.Games {
+		resp.Games[i].ParseRanks()
+	}
+}
+
 func (g *GameUseCase) GetListOfArchiveYears(ctx context.Context) (*game.ArchiveYearsResponse, error) {
 	resp, err := g.store.GetArchiveYears(ctx)
 	if err == nil {
@@ -313,6 +1306,7 @@ func (g *GameUseCase) GetGameFromArchiveById(ctx context.Context, gameFromArchiv
 	if err != nil {
 		return nil, err
 	}
+	foundGame.ParseRanks()
 
 	return foundGame, nil
 }
@@ -2,32 +2,164 @@ package katago
 
 import (
 	"context"
+	"strings"
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/bot"
+	"team_exe/internal/domain/coord"
 	"team_exe/internal/domain/game"
+	"team_exe/internal/domain/rules"
+	"team_exe/internal/errors"
 	katagoRPC "team_exe/microservices/proto"
 )
 
-func GenMove(ctx context.Context, moves game.Moves, katagoGRPC katagoRPC.KatagoServiceClient) (game.Move, error) {
-	movesRPC := ConvertDomainMovesToRPC(moves)
+// GenMove запрашивает у KataGo ход в позиции position, играя с силой strength. Вместе с ходом
+// возвращает ответ движка с разбором позиции. Если бот сдаётся, возвращает errors.ErrBotResigned.
+func GenMove(ctx context.Context, position game.BotPosition, strength bot.Strength, katagoGRPC katagoRPC.KatagoServiceClient) (game.Move, game.BotResponse, error) {
+	position, err := withDefaults(position)
+	if err != nil {
+		return game.Move{}, game.BotResponse{}, err
+	}
+	color, err := NextColor(position)
+	if err != nil {
+		return game.Move{}, game.BotResponse{}, err
+	}
 
-	botResponse, err := katagoGRPC.GenerateMove(ctx, &movesRPC)
+	movesRPC, err := ConvertDomainMovesToRPC(game.Moves{Moves: position.Moves}, position.BoardSize)
+	if err != nil {
+		return game.Move{}, game.BotResponse{}, err
+	}
+	movesRPC.Strength = ConvertStrengthToRPC(strength)
+	movesRPC.Settings, err = ConvertSettingsToRPC(position, color)
 	if err != nil {
-		return game.Move{}, err
+		return game.Move{}, game.BotResponse{}, err
 	}
 
+	botResponseRPC, err := katagoGRPC.GenerateMove(ctx, &movesRPC)
+	if err != nil {
+		return game.Move{}, game.BotResponse{}, err
+	}
+	botResponse := ConvertRPCResponseToDomain(botResponseRPC)
+
+	if strings.EqualFold(botResponse.BotMove, "resign") {
+		return game.Move{}, botResponse, errors.ErrBotResigned
+	}
+	botPoint, err := coord.ParseGTP(botResponse.BotMove, position.BoardSize)
+	if err != nil {
+		return game.Move{}, botResponse, err
+	}
+
+	coordinates := botPoint.SGF()
+	if botPoint.Pass {
+		coordinates = "pass"
+	}
 	return game.Move{
-		Coordinates: botResponse.BotMove,
-		Color:       "w",
-	}, nil
+		Coordinates: coordinates,
+		Color:       color.String(),
+	}, botResponse, nil
+}
+
+// withDefaults проверяет настройки позиции и заполняет пропущенные: доска 19x19,
+// правила по умолчанию и коми правил с учётом форы.
+func withDefaults(position game.BotPosition) (game.BotPosition, error) {
+	if position.BoardSize == 0 {
+		position.BoardSize = coord.DefaultBoardSize
+	}
+	if position.BoardSize < 2 || position.BoardSize > board.MaxSize {
+		return game.BotPosition{}, errors.ErrBadBoardSize
+	}
+	ruleset, err := rules.Parse(position.Rules)
+	if err != nil {
+		return game.BotPosition{}, err
+	}
+	position.Rules = ruleset.Name
+	if position.Komi == 0 {
+		position.Komi = ruleset.DefaultKomi(len(position.Setup))
+	}
+	return position, nil
+}
+
+// ConvertRPCResponseToDomain переводит ответ KataGo вместе с разбором позиции.
+func ConvertRPCResponseToDomain(resp *katagoRPC.BotResponse) game.BotResponse {
+	diagnostics := resp.GetDiagnostics()
+	bestTen := make([]game.MovePSV, 0, len(diagnostics.GetBestTen()))
+	for _, m := range diagnostics.GetBestTen() {
+		bestTen = append(bestTen, game.MovePSV{
+			Move: m.GetMove(),
+			PSV:  int(m.GetPsv()),
+		})
+	}
+	return game.BotResponse{
+		BotMove: resp.GetBotMove(),
+		Diagnostics: game.Diagnostics{
+			BestTen: bestTen,
+			BotMove: diagnostics.GetBotMove(),
+			Score:   diagnostics.GetScore(),
+			WinProb: diagnostics.GetWinProb(),
+		},
+		RequestID: resp.GetRequestId(),
+	}
 }
 
-func ConvertDomainMovesToRPC(movesDomain game.Moves) katagoRPC.Moves {
+// NextColor возвращает цвет, чей ход в позиции: заданный явно или по очерёдности ходов.
+// Без ходов после форы из двух и более камней первыми ходят белые, иначе чёрные.
+func NextColor(position game.BotPosition) (board.Color, error) {
+	if position.ToMove != "" {
+		return board.ParseColor(position.ToMove)
+	}
+	if len(position.Moves) == 0 {
+		if len(position.Setup) >= 2 {
+			return board.White, nil
+		}
+		return board.Black, nil
+	}
+	last, err := board.ParseColor(position.Moves[len(position.Moves)-1].Color)
+	if err != nil {
+		return board.Black, nil
+	}
+	return last.Opponent(), nil
+}
+
+// ConvertStrengthToRPC переводит профиль силы бота в сообщение KataGo.
+func ConvertStrengthToRPC(strength bot.Strength) *katagoRPC.Strength {
+	return &katagoRPC.Strength{
+		MaxVisits:   int32(strength.MaxVisits),
+		MaxPlayouts: int32(strength.MaxPlayouts),
+		MaxTime:     strength.MaxTime,
+		HumanRank:   strength.HumanRank,
+		Temperature: strength.Temperature,
+	}
+}
+
+// ConvertDomainMovesToRPC переводит ходы в вершины GTP, которые понимает KataGo.
+// Координаты ходов могут быть записаны в любой нотации, поддерживаемой coord.Parse.
+func ConvertDomainMovesToRPC(movesDomain game.Moves, boardSize int) (katagoRPC.Moves, error) {
 	rpcMoves := make([]*katagoRPC.Move, 0)
 	for _, m := range movesDomain.Moves {
+		point, err := coord.Parse(m.Coordinates, boardSize)
+		if err != nil {
+			return katagoRPC.Moves{}, err
+		}
 		move := &katagoRPC.Move{
-			Coordinates: m.Coordinates,
+			Coordinates: point.GTP(boardSize),
 			Color:       m.Color,
 		}
 		rpcMoves = append(rpcMoves, move)
 	}
-	return katagoRPC.Moves{Moves: rpcMoves}
+	return katagoRPC.Moves{Moves: rpcMoves}, nil
+}
+
+// ConvertSettingsToRPC переводит настройки позиции и цвет, чей ход, в сообщение KataGo.
+// Камни начальной расстановки записываются вершинами GTP.
+func ConvertSettingsToRPC(position game.BotPosition, toMove board.Color) (*katagoRPC.GameSettings, error) {
+	setup, err := ConvertDomainMovesToRPC(game.Moves{Moves: position.Setup}, position.BoardSize)
+	if err != nil {
+		return nil, err
+	}
+	return &katagoRPC.GameSettings{
+		BoardSize: int32(position.BoardSize),
+		Komi:      position.Komi,
+		Rules:     position.Rules,
+		Setup:     setup.Moves,
+		ToMove:    toMove.String(),
+	}, nil
 }
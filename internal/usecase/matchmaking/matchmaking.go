@@ -0,0 +1,258 @@
+package matchmaking
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"team_exe/internal/domain/board"
+	"team_exe/internal/domain/game"
+	"team_exe/internal/domain/matchmaking"
+	"team_exe/internal/errors"
+	"team_exe/internal/usecase/auth"
+	gameuc "team_exe/internal/usecase/game"
+)
+
+type QueueStore interface {
+	Enqueue(ctx context.Context, req matchmaking.Request) error
+	Dequeue(ctx context.Context, userID string) (bool, error)
+	GetRequest(ctx context.Context, userID string) (*matchmaking.Request, error)
+	ListRequests(ctx context.Context) ([]matchmaking.Request, error)
+	TakePair(ctx context.Context, first, second string) (bool, error)
+	SaveMatch(ctx context.Context, userID string, match matchmaking.Match) error
+	GetMatch(ctx context.Context, userID string) (*matchmaking.Match, error)
+	ClearMatch(ctx context.Context, userID string) error
+	Lock(ctx context.Context, owner string) (bool, error)
+	Unlock(ctx context.Context, owner string) error
+}
+
+type MatchmakingUseCase struct {
+	store       QueueStore
+	gameUsecase *gameuc.GameUseCase
+	userUsecase *auth.UserUsecaseHandler
+	window      matchmaking.Window
+	owner       string // метка экземпляра сервера для блокировки подбора
+}
+
+func NewMatchmakingUseCase(store QueueStore, games *gameuc.GameUseCase, users *auth.UserUsecaseHandler, window matchmaking.Window) *MatchmakingUseCase {
+	return &MatchmakingUseCase{
+		store:       store,
+		gameUsecase: games,
+		userUsecase: users,
+		window:      window.WithDefaults(),
+		owner:       uuid.New().String(),
+	}
+}
+
+// Join ставит игрока в очередь подбора. Повторный вызов заменяет настройки заявки,
+// но сохраняет время ожидания, если настройки не изменились.
+func (m *MatchmakingUseCase) Join(ctx context.Context, userID string, req matchmaking.JoinRequest) (matchmaking.Status, error) {
+	if req.BoardSize < 2 || req.BoardSize > board.MaxSize {
+		return matchmaking.Status{}, errors.ErrBadBoardSize
+	}
+	if err := req.TimeControl.Validate(); err != nil {
+		return matchmaking.Status{}, err
+	}
+
+	inGame, err := m.gameUsecase.HasUserActiveGamesByUserId(ctx, userID)
+	if err != nil {
+		return matchmaking.Status{}, err
+	}
+	if inGame {
+		return matchmaking.Status{}, errors.ErrAlreadyInGame
+	}
+
+	player, err := m.userUsecase.GetUserByUserId(ctx, userID)
+	if err != nil {
+		return matchmaking.Status{}, err
+	}
+
+	request := matchmaking.Request{
+		UserID:      userID,
+		Rating:      player.Glicko().Rating,
+		BoardSize:   req.BoardSize,
+		TimeControl: req.TimeControl,
+		Rated:       req.Rated,
+		JoinedAt:    time.Now(),
+	}
+	previous, err := m.store.GetRequest(ctx, userID)
+	if err != nil {
+		return matchmaking.Status{}, err
+	}
+	if previous != nil && previous.BoardSize == request.BoardSize && previous.TimeControl == request.TimeControl && previous.Rated == request.Rated {
+		request.JoinedAt = previous.JoinedAt
+	}
+
+	if err = m.store.ClearMatch(ctx, userID); err != nil {
+		return matchmaking.Status{}, err
+	}
+	if err = m.store.Enqueue(ctx, request); err != nil {
+		return matchmaking.Status{}, err
+	}
+	return m.searching(request, time.Now()), nil
+}
+
+// Leave убирает игрока из очереди подбора.
+func (m *MatchmakingUseCase) Leave(ctx context.Context, userID string) error {
+	_, err := m.store.Dequeue(ctx, userID)
+	return err
+}
+
+// Status сообщает, ищет ли игрок соперника или партия для него уже создана.
+func (m *MatchmakingUseCase) Status(ctx context.Context, userID string) (matchmaking.Status, error) {
+	match, err := m.store.GetMatch(ctx, userID)
+	if err != nil {
+		return matchmaking.Status{}, err
+	}
+	if match != nil {
+		return matchmaking.Status{State: matchmaking.StateMatched, Match: match}, nil
+	}
+
+	request, err := m.store.GetRequest(ctx, userID)
+	if err != nil {
+		return matchmaking.Status{}, err
+	}
+	if request == nil {
+		return matchmaking.Status{State: matchmaking.StateIdle}, nil
+	}
+	return m.searching(*request, time.Now()), nil
+}
+
+func (m *MatchmakingUseCase) searching(request matchmaking.Request, now time.Time) matchmaking.Status {
+	waited := now.Sub(request.JoinedAt)
+	return matchmaking.Status{
+		State:         matchmaking.StateSearching,
+		WaitedSeconds: int(waited.Seconds()),
+		RatingWindow:  m.window.At(waited),
+	}
+}
+
+// TryMatch сводит подходящие заявки очереди и создаёт для каждой пары партию.
+// Пары сводит только экземпляр, взявший блокировку, остальные пропускают проход.
+// Возвращает число созданных партий.
+func (m *MatchmakingUseCase) TryMatch(ctx context.Context) (int, error) {
+	locked, err := m.store.Lock(ctx, m.owner)
+	if err != nil || !locked {
+		return 0, err
+	}
+	defer m.store.Unlock(context.WithoutCancel(ctx), m.owner)
+
+	requests, err := m.store.ListRequests(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, pair := range matchmaking.Pair(requests, m.window, time.Now()) {
+		// игрок мог войти в партию по ключу, пока ждал в очереди
+		available, err := m.available(ctx, pair)
+		if err != nil {
+			return created, err
+		}
+		if !available {
+			continue
+		}
+		taken, err := m.store.TakePair(ctx, pair[0].UserID, pair[1].UserID)
+		if err != nil {
+			return created, err
+		}
+		if !taken {
+			continue
+		}
+		if err = m.createMatch(ctx, pair[0], pair[1]); err != nil {
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
+
+// available проверяет, что оба игрока пары ещё не играют. Заявку игрока, который
+// уже сел за партию, убирает из очереди.
+func (m *MatchmakingUseCase) available(ctx context.Context, pair [2]matchmaking.Request) (bool, error) {
+	available := true
+	for _, request := range pair {
+		inGame, err := m.gameUsecase.HasUserActiveGamesByUserId(ctx, request.UserID)
+		if err != nil {
+			return false, err
+		}
+		if !inGame {
+			continue
+		}
+		available = false
+		if _, err = m.store.Dequeue(ctx, request.UserID); err != nil {
+			return false, err
+		}
+	}
+	return available, nil
+}
+
+// createMatch создаёт партию для пары. Чёрными играет игрок с меньшим рейтингом.
+// Если партию не удалось довести до конца, она удаляется, а заявки возвращаются в очередь
+// с прежним временем ожидания.
+func (m *MatchmakingUseCase) createMatch(ctx context.Context, first, second matchmaking.Request) error {
+	black, white := first, second
+	if white.Rating < black.Rating {
+		black, white = white, black
+	}
+
+	gameKeySecret, err := m.startMatch(ctx, black, white)
+	if err == nil {
+		return nil
+	}
+	if rollbackErr := m.rollbackMatch(context.WithoutCancel(ctx), gameKeySecret, black, white); rollbackErr != nil {
+		return fmt.Errorf("%w; откат подбора: %w", err, rollbackErr)
+	}
+	return err
+}
+
+// startMatch создаёт партию, сажает за неё обоих игроков и сообщает им о ней.
+// Возвращает секретный ключ партии, если она успела появиться в базе.
+func (m *MatchmakingUseCase) startMatch(ctx context.Context, black, white matchmaking.Request) (string, error) {
+	err, gameKeyPublic, gameKeySecret := m.gameUsecase.CreateGame(ctx, game.CreateGameRequest{
+		BoardSize:      black.BoardSize,
+		IsCreatorBlack: true,
+		Rated:          black.Rated,
+		TimeControl:    black.TimeControl,
+		IsPublic:       true,
+	}, black.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	play, err := m.gameUsecase.GetGameBySecreteKey(ctx, gameKeySecret)
+	if err != nil {
+		return gameKeySecret, err
+	}
+	if _, err = m.gameUsecase.JoinGame(ctx, play, white.UserID); err != nil {
+		return gameKeySecret, err
+	}
+
+	if err = m.store.SaveMatch(ctx, black.UserID, matchmaking.Match{
+		GameKeyPublic: gameKeyPublic,
+		Color:         board.Black.String(),
+		OpponentID:    white.UserID,
+	}); err != nil {
+		return gameKeySecret, err
+	}
+	return gameKeySecret, m.store.SaveMatch(ctx, white.UserID, matchmaking.Match{
+		GameKeyPublic: gameKeyPublic,
+		Color:         board.White.String(),
+		OpponentID:    black.UserID,
+	})
+}
+
+// rollbackMatch удаляет недосозданную партию и сообщения о ней и возвращает заявки пары в очередь.
+func (m *MatchmakingUseCase) rollbackMatch(ctx context.Context, gameKeySecret string, black, white matchmaking.Request) error {
+	var errs []error
+	if gameKeySecret != "" {
+		errs = append(errs, m.gameUsecase.DeleteGame(ctx, gameKeySecret))
+	}
+	for _, request := range []matchmaking.Request{black, white} {
+		errs = append(errs, m.store.ClearMatch(ctx, request.UserID), m.store.Enqueue(ctx, request))
+	}
+	return stderrors.Join(errs...)
+}
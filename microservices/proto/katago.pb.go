@@ -256,6 +256,8 @@ func (x *Move) GetCoordinates() string {
 type Moves struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Moves         []*Move                `protobuf:"bytes,1,rep,name=moves,proto3" json:"moves,omitempty"`
+	Strength      *Strength              `protobuf:"bytes,2,opt,name=strength,proto3" json:"strength,omitempty"`
+	Settings      *GameSettings          `protobuf:"bytes,3,opt,name=settings,proto3" json:"settings,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -297,6 +299,172 @@ func (x *Moves) GetMoves() []*Move {
 	return nil
 }
 
+func (x *Moves) GetStrength() *Strength {
+	if x != nil {
+		return x.Strength
+	}
+	return nil
+}
+
+func (x *Moves) GetSettings() *GameSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+type Strength struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxVisits     int32                  `protobuf:"varint,1,opt,name=max_visits,json=maxVisits,proto3" json:"max_visits,omitempty"`
+	MaxPlayouts   int32                  `protobuf:"varint,2,opt,name=max_playouts,json=maxPlayouts,proto3" json:"max_playouts,omitempty"`
+	MaxTime       float64                `protobuf:"fixed64,3,opt,name=max_time,json=maxTime,proto3" json:"max_time,omitempty"`
+	HumanRank     string                 `protobuf:"bytes,4,opt,name=human_rank,json=humanRank,proto3" json:"human_rank,omitempty"`
+	Temperature   float64                `protobuf:"fixed64,5,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Strength) Reset() {
+	*x = Strength{}
+	mi := &file_katago_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Strength) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Strength) ProtoMessage() {}
+
+func (x *Strength) ProtoReflect() protoreflect.Message {
+	mi := &file_katago_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Strength.ProtoReflect.Descriptor instead.
+func (*Strength) Descriptor() ([]byte, []int) {
+	return file_katago_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Strength) GetMaxVisits() int32 {
+	if x != nil {
+		return x.MaxVisits
+	}
+	return 0
+}
+
+func (x *Strength) GetMaxPlayouts() int32 {
+	if x != nil {
+		return x.MaxPlayouts
+	}
+	return 0
+}
+
+func (x *Strength) GetMaxTime() float64 {
+	if x != nil {
+		return x.MaxTime
+	}
+	return 0
+}
+
+func (x *Strength) GetHumanRank() string {
+	if x != nil {
+		return x.HumanRank
+	}
+	return ""
+}
+
+func (x *Strength) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+type GameSettings struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BoardSize     int32                  `protobuf:"varint,1,opt,name=board_size,json=boardSize,proto3" json:"board_size,omitempty"`
+	Komi          float64                `protobuf:"fixed64,2,opt,name=komi,proto3" json:"komi,omitempty"`
+	Rules         string                 `protobuf:"bytes,3,opt,name=rules,proto3" json:"rules,omitempty"`
+	Setup         []*Move                `protobuf:"bytes,4,rep,name=setup,proto3" json:"setup,omitempty"`
+	ToMove        string                 `protobuf:"bytes,5,opt,name=to_move,json=toMove,proto3" json:"to_move,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GameSettings) Reset() {
+	*x = GameSettings{}
+	mi := &file_katago_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GameSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameSettings) ProtoMessage() {}
+
+func (x *GameSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_katago_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameSettings.ProtoReflect.Descriptor instead.
+func (*GameSettings) Descriptor() ([]byte, []int) {
+	return file_katago_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GameSettings) GetBoardSize() int32 {
+	if x != nil {
+		return x.BoardSize
+	}
+	return 0
+}
+
+func (x *GameSettings) GetKomi() float64 {
+	if x != nil {
+		return x.Komi
+	}
+	return 0
+}
+
+func (x *GameSettings) GetRules() string {
+	if x != nil {
+		return x.Rules
+	}
+	return ""
+}
+
+func (x *GameSettings) GetSetup() []*Move {
+	if x != nil {
+		return x.Setup
+	}
+	return nil
+}
+
+func (x *GameSettings) GetToMove() string {
+	if x != nil {
+		return x.ToMove
+	}
+	return ""
+}
+
 var File_katago_proto protoreflect.FileDescriptor
 
 var file_katago_proto_rawDesc = string([]byte{
@@ -325,15 +493,41 @@ var file_katago_proto_rawDesc = string([]byte{
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x12, 0x20, 0x0a,
 	0x0b, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73, 0x22,
-	0x2b, 0x0a, 0x05, 0x4d, 0x6f, 0x76, 0x65, 0x73, 0x12, 0x22, 0x0a, 0x05, 0x6d, 0x6f, 0x76, 0x65,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x6b, 0x61, 0x74, 0x61, 0x67, 0x6f,
-	0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x52, 0x05, 0x6d, 0x6f, 0x76, 0x65, 0x73, 0x32, 0x43, 0x0a, 0x0d,
-	0x4b, 0x61, 0x74, 0x61, 0x67, 0x6f, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x32, 0x0a,
-	0x0c, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x4d, 0x6f, 0x76, 0x65, 0x12, 0x0d, 0x2e,
-	0x6b, 0x61, 0x74, 0x61, 0x67, 0x6f, 0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x73, 0x1a, 0x13, 0x2e, 0x6b,
-	0x61, 0x74, 0x61, 0x67, 0x6f, 0x2e, 0x42, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x42, 0x0b, 0x5a, 0x09, 0x2e, 0x2f, 0x3b, 0x6b, 0x61, 0x74, 0x61, 0x67, 0x6f, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x8b, 0x01, 0x0a, 0x05, 0x4d, 0x6f, 0x76, 0x65, 0x73, 0x12, 0x22, 0x0a, 0x05, 0x6d, 0x6f, 0x76,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x6b, 0x61, 0x74, 0x61, 0x67,
+	0x6f, 0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x52, 0x05, 0x6d, 0x6f, 0x76, 0x65, 0x73, 0x12, 0x2c, 0x0a,
+	0x08, 0x73, 0x74, 0x72, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x6b, 0x61, 0x74, 0x61, 0x67, 0x6f, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x6e, 0x67, 0x74,
+	0x68, 0x52, 0x08, 0x73, 0x74, 0x72, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x30, 0x0a, 0x08, 0x73,
+	0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x6b, 0x61, 0x74, 0x61, 0x67, 0x6f, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x65, 0x74, 0x74, 0x69,
+	0x6e, 0x67, 0x73, 0x52, 0x08, 0x73, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x22, 0xa8, 0x01,
+	0x0a, 0x08, 0x53, 0x74, 0x72, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61,
+	0x78, 0x5f, 0x76, 0x69, 0x73, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09,
+	0x6d, 0x61, 0x78, 0x56, 0x69, 0x73, 0x69, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61, 0x78,
+	0x5f, 0x70, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0b, 0x6d, 0x61, 0x78, 0x50, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x73, 0x12, 0x19, 0x0a, 0x08,
+	0x6d, 0x61, 0x78, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07,
+	0x6d, 0x61, 0x78, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x68, 0x75, 0x6d, 0x61, 0x6e,
+	0x5f, 0x72, 0x61, 0x6e, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x68, 0x75, 0x6d,
+	0x61, 0x6e, 0x52, 0x61, 0x6e, 0x6b, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x74, 0x65, 0x6d,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x94, 0x01, 0x0a, 0x0c, 0x47, 0x61, 0x6d,
+	0x65, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6f, 0x61,
+	0x72, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x62,
+	0x6f, 0x61, 0x72, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x6f, 0x6d, 0x69,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x6b, 0x6f, 0x6d, 0x69, 0x12, 0x14, 0x0a, 0x05,
+	0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x75, 0x6c,
+	0x65, 0x73, 0x12, 0x22, 0x0a, 0x05, 0x73, 0x65, 0x74, 0x75, 0x70, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x6b, 0x61, 0x74, 0x61, 0x67, 0x6f, 0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x52,
+	0x05, 0x73, 0x65, 0x74, 0x75, 0x70, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x6f, 0x5f, 0x6d, 0x6f, 0x76,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x6f, 0x4d, 0x6f, 0x76, 0x65, 0x32,
+	0x43, 0x0a, 0x0d, 0x4b, 0x61, 0x74, 0x61, 0x67, 0x6f, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x12, 0x32, 0x0a, 0x0c, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x4d, 0x6f, 0x76, 0x65,
+	0x12, 0x0d, 0x2e, 0x6b, 0x61, 0x74, 0x61, 0x67, 0x6f, 0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x73, 0x1a,
+	0x13, 0x2e, 0x6b, 0x61, 0x74, 0x61, 0x67, 0x6f, 0x2e, 0x42, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x0b, 0x5a, 0x09, 0x2e, 0x2f, 0x3b, 0x6b, 0x61, 0x74, 0x61, 0x67,
+	0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 })
 
 var (
@@ -348,25 +542,30 @@ func file_katago_proto_rawDescGZIP() []byte {
 	return file_katago_proto_rawDescData
 }
 
-var file_katago_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_katago_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_katago_proto_goTypes = []any{
-	(*BotResponse)(nil), // 0: katago.BotResponse
-	(*Diagnostics)(nil), // 1: katago.Diagnostics
-	(*MovePSV)(nil),     // 2: katago.MovePSV
-	(*Move)(nil),        // 3: katago.Move
-	(*Moves)(nil),       // 4: katago.Moves
+	(*BotResponse)(nil),  // 0: katago.BotResponse
+	(*Diagnostics)(nil),  // 1: katago.Diagnostics
+	(*MovePSV)(nil),      // 2: katago.MovePSV
+	(*Move)(nil),         // 3: katago.Move
+	(*Moves)(nil),        // 4: katago.Moves
+	(*Strength)(nil),     // 5: katago.Strength
+	(*GameSettings)(nil), // 6: katago.GameSettings
 }
 var file_katago_proto_depIdxs = []int32{
 	1, // 0: katago.BotResponse.diagnostics:type_name -> katago.Diagnostics
 	2, // 1: katago.Diagnostics.best_ten:type_name -> katago.MovePSV
 	3, // 2: katago.Moves.moves:type_name -> katago.Move
-	4, // 3: katago.KatagoService.GenerateMove:input_type -> katago.Moves
-	0, // 4: katago.KatagoService.GenerateMove:output_type -> katago.BotResponse
-	4, // [4:5] is the sub-list for method output_type
-	3, // [3:4] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	5, // 3: katago.Moves.strength:type_name -> katago.Strength
+	6, // 4: katago.Moves.settings:type_name -> katago.GameSettings
+	3, // 5: katago.GameSettings.setup:type_name -> katago.Move
+	4, // 6: katago.KatagoService.GenerateMove:input_type -> katago.Moves
+	0, // 7: katago.KatagoService.GenerateMove:output_type -> katago.BotResponse
+	7, // [7:8] is the sub-list for method output_type
+	6, // [6:7] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_katago_proto_init() }
@@ -380,7 +579,7 @@ func file_katago_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_katago_proto_rawDesc), len(file_katago_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
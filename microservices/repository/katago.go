@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"net/http"
+	"team_exe/internal/domain/bot"
 	"team_exe/internal/domain/game"
 
 	"team_exe/internal/adapters"
@@ -39,16 +40,47 @@ func generateUUID() string {
 	return uuid.New().String()
 }
 
+// defaultBoardSize размер доски, если клиент не передал настройки позиции.
+const defaultBoardSize = 19
+
 type SelectMoveRequest struct {
-	BoardSize int      `json:"board_size"`
-	Moves     []string `json:"moves"`
+	RequestID     string     `json:"request_id"` // движок пишет идентификатор в свои логи и возвращает в ответе
+	BoardSize     int        `json:"board_size"`
+	Komi          float64    `json:"komi,omitempty"`
+	Rules         string     `json:"rules,omitempty"`
+	InitialStones [][]string `json:"initial_stones,omitempty"` // камни расстановки парами цвет и вершина, например ["B", "D4"]
+	ToMove        string     `json:"to_move,omitempty"`
+	Moves         []string   `json:"moves"`
+	MaxVisits     int        `json:"max_visits,omitempty"`
+	MaxPlayouts   int        `json:"max_playouts,omitempty"`
+	MaxTime       float64    `json:"max_time,omitempty"`
+	HumanProfile  string     `json:"human_profile,omitempty"` // профиль человекоподобной модели KataGo, например rank_5k
+	Temperature   float64    `json:"temperature,omitempty"`
 }
 
-func (k *KatagoRepository) GenerateMove(ctx context.Context, moves []string) (game.BotResponse, error) {
-	reqBody, err := json.Marshal(SelectMoveRequest{
-		BoardSize: 19,
-		Moves:     moves,
-	})
+func (k *KatagoRepository) GenerateMove(ctx context.Context, position game.BotPosition, strength bot.Strength) (game.BotResponse, error) {
+	selectMove := SelectMoveRequest{
+		RequestID:   generateUUID(),
+		BoardSize:   position.BoardSize,
+		Komi:        position.Komi,
+		Rules:       position.Rules,
+		ToMove:      position.ToMove,
+		Moves:       extractCoordinates(position.Moves),
+		MaxVisits:   strength.MaxVisits,
+		MaxPlayouts: strength.MaxPlayouts,
+		MaxTime:     strength.MaxTime,
+		Temperature: strength.Temperature,
+	}
+	if selectMove.BoardSize == 0 {
+		selectMove.BoardSize = defaultBoardSize
+	}
+	for _, stone := range position.Setup {
+		selectMove.InitialStones = append(selectMove.InitialStones, []string{stone.Color, stone.Coordinates})
+	}
+	if strength.HumanRank != "" {
+		selectMove.HumanProfile = "rank_" + strength.HumanRank
+	}
+	reqBody, err := json.Marshal(selectMove)
 	if err != nil {
 		return game.BotResponse{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
@@ -58,6 +90,7 @@ func (k *KatagoRepository) GenerateMove(ctx context.Context, moves []string) (ga
 	if err != nil {
 		return game.BotResponse{}, fmt.Errorf("failed to create request: %w", err)
 	}
+	k.log.Infof("Запрос хода у KataGo, идентификатор %s", selectMove.RequestID)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := k.client.Do(req)
@@ -74,6 +107,18 @@ func (k *KatagoRepository) GenerateMove(ctx context.Context, moves []string) (ga
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return game.BotResponse{}, fmt.Errorf("failed to decode response: %w", err)
 	}
+	// по идентификатору запрос можно найти в логах движка и сервиса
+	if result.RequestID == "" {
+		result.RequestID = selectMove.RequestID
+	}
 
 	return result, nil
 }
+
+func extractCoordinates(moves []game.Move) []string {
+	coords := make([]string, 0)
+	for _, m := range moves {
+		coords = append(coords, m.Coordinates)
+	}
+	return coords
+}
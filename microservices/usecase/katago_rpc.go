@@ -2,12 +2,13 @@ package usecase
 
 import (
 	"context"
+	"team_exe/internal/domain/bot"
 	"team_exe/internal/domain/game"
 	katagoRPC "team_exe/microservices/proto"
 )
 
 type KatagoStore interface {
-	GenerateMove(ctx context.Context, moves []string) (game.BotResponse, error)
+	GenerateMove(ctx context.Context, position game.BotPosition, strength bot.Strength) (game.BotResponse, error)
 }
 
 type KatagoUseCase struct {
@@ -23,33 +24,55 @@ func NewKatagoUseCase(store KatagoStore) *KatagoUseCase {
 
 func (k *KatagoUseCase) GenerateMove(ctx context.Context, in *katagoRPC.Moves) (*katagoRPC.BotResponse, error) {
 	// Преобразуем RPC-структуру в доменную модель
-	moves := ConvertRPCMovesToDomain(*in)
-	movesStrings := extractCoordinates(moves)
+	position := ConvertRPCSettingsToDomain(in.GetSettings())
+	position.Moves = ConvertRPCMovesToDomain(in).Moves
 
 	// Вызов логики генерации хода через store
-	botResponseDomain, err := k.store.GenerateMove(ctx, movesStrings)
+	botResponseDomain, err := k.store.GenerateMove(ctx, position, ConvertRPCStrengthToDomain(in.GetStrength()))
 	if err != nil {
 		return nil, err
 	}
 
 	// Преобразуем доменный ответ в RPC-структуру
 	resp := &katagoRPC.BotResponse{
-		BotMove: botResponseDomain.BotMove,
-		//RequestId: botResponseDomain.RequestId,
-		// Если у вас есть дополнительные поля, их тоже нужно преобразовать
+		BotMove:     botResponseDomain.BotMove,
+		Diagnostics: ConvertDiagnosticsToRPC(botResponseDomain.Diagnostics),
+		RequestId:   botResponseDomain.RequestID,
 	}
 	return resp, nil
 }
 
-func extractCoordinates(moves game.Moves) []string {
-	coords := make([]string, 0)
-	for _, m := range moves.Moves {
-		coords = append(coords, m.Coordinates)
+// ConvertDiagnosticsToRPC переводит разбор позиции от движка: лучшие ходы, оценку счёта
+// и вероятность победы.
+func ConvertDiagnosticsToRPC(diagnostics game.Diagnostics) *katagoRPC.Diagnostics {
+	bestTen := make([]*katagoRPC.MovePSV, 0, len(diagnostics.BestTen))
+	for _, m := range diagnostics.BestTen {
+		bestTen = append(bestTen, &katagoRPC.MovePSV{
+			Move: m.Move,
+			Psv:  int32(m.PSV),
+		})
+	}
+	return &katagoRPC.Diagnostics{
+		BestTen: bestTen,
+		BotMove: diagnostics.BotMove,
+		Score:   diagnostics.Score,
+		WinProb: diagnostics.WinProb,
+	}
+}
+
+// ConvertRPCStrengthToDomain переводит профиль силы из запроса. Пустой профиль оставляет
+// настройки движка по умолчанию.
+func ConvertRPCStrengthToDomain(strength *katagoRPC.Strength) bot.Strength {
+	return bot.Strength{
+		MaxVisits:   int(strength.GetMaxVisits()),
+		MaxPlayouts: int(strength.GetMaxPlayouts()),
+		MaxTime:     strength.GetMaxTime(),
+		HumanRank:   strength.GetHumanRank(),
+		Temperature: strength.GetTemperature(),
 	}
-	return coords
 }
 
-func ConvertRPCMovesToDomain(movesOld katagoRPC.Moves) game.Moves {
+func ConvertRPCMovesToDomain(movesOld *katagoRPC.Moves) game.Moves {
 	domainMoves := make([]game.Move, 0)
 	for _, m := range movesOld.Moves {
 		move := game.Move{
@@ -60,3 +83,22 @@ func ConvertRPCMovesToDomain(movesOld katagoRPC.Moves) game.Moves {
 	}
 	return game.Moves{Moves: domainMoves}
 }
+
+// ConvertRPCSettingsToDomain переводит настройки позиции из запроса. Без настроек
+// движок играет на доске 19x19 со своими правилами и коми.
+func ConvertRPCSettingsToDomain(settings *katagoRPC.GameSettings) game.BotPosition {
+	setup := make([]game.Move, 0, len(settings.GetSetup()))
+	for _, m := range settings.GetSetup() {
+		setup = append(setup, game.Move{
+			Coordinates: m.GetCoordinates(),
+			Color:       m.GetColor(),
+		})
+	}
+	return game.BotPosition{
+		BoardSize: int(settings.GetBoardSize()),
+		Komi:      settings.GetKomi(),
+		Rules:     settings.GetRules(),
+		Setup:     setup,
+		ToMove:    settings.GetToMove(),
+	}
+}